@@ -2,39 +2,97 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	serverAddr = "localhost:50051"
 	chunkSize = 64 * 1024
+
+	uploadAttempts = 5
+
+	// rateLimitAttempts is how often a call rejected over a rate limit is
+	// tried in total, waiting as long as the server suggests in between.
+	rateLimitAttempts = 5
+)
+
+// namespace is sent with every file request; empty means the server's
+// default namespace.
+var namespace = flag.String("namespace", "", "namespace to work in (default \"default\")")
+
+// apiKey identifies the client to the server's per-client limits; without it
+// the client is known by its address.
+var apiKey = flag.String("api-key", os.Getenv("FILE_SERVICE_API_KEY"), "API key sent with every request (default $FILE_SERVICE_API_KEY)")
+
+// The connection uses TLS if -tls is set or any other TLS flag is given.
+// -tls-cert and -tls-key are the client certificate presented for mTLS.
+var (
+	useTLS  = flag.Bool("tls", false, "connect over TLS, verifying the server against the system roots or -tls-ca")
+	tlsCA   = flag.String("tls-ca", "", "PEM CA bundle to verify the server certificate with")
+	tlsCert = flag.String("tls-cert", "", "PEM client certificate to present, together with -tls-key")
+	tlsKey  = flag.String("tls-key", "", "PEM private key of -tls-cert")
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 1 {
 		fmt.Println("Usage:")
-		fmt.Println(" client upload <filepath>")
-		fmt.Println(" client download <file_id> <output_path>")
-		fmt.Println(" client list")
+		fmt.Println(" client [--namespace <name>] [--api-key <key>]")
+		fmt.Println("        [--tls] [--tls-ca <file>] [--tls-cert <file> --tls-key <file>] <command> [args...]")
+		fmt.Println()
+		fmt.Println(" client upload [--ttl <duration> | --expires-at <time>] <filepath>")
+		fmt.Println(" client resume <session_id> <filepath>")
+		fmt.Println(" client update [--ttl <duration> | --expires-at <time>] <file_id> <filepath>")
+		fmt.Println(" client download <file_id> <output_path> [version]")
+		fmt.Println(" client list [--order created_at|name|size] [--desc] [--prefix <p>]")
+		fmt.Println("             [--after <time>] [--before <time>] [--min-size <n>] [--max-size <n>] [--page-size <n>]")
+		fmt.Println(" client list-all [list options]")
+		fmt.Println(" client info <file_id>")
+		fmt.Println(" client delete <file_id>")
+		fmt.Println(" client trash")
+		fmt.Println(" client restore <file_id>")
+		fmt.Println(" client versions <file_id>")
+		fmt.Println(" client restore-version <file_id> <version>")
+		fmt.Println(" client usage")
+		fmt.Println(" client namespaces")
+		fmt.Println(" client create-namespace <name> [<quota_bytes> <quota_files>]")
+		fmt.Println(" client delete-namespace <name>")
 		fmt.Println(" client test-limits")
 		os.Exit(1)
 	}
 
-	conn, err := grpc.NewClient(
-		serverAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	opts, err := dialOptions()
+	if err != nil {
+		fmt.Printf("Failed to load TLS credentials: %v\n", err)
+		os.Exit(1)
+	}
+
+	conn, err := grpc.NewClient(serverAddr, append(opts, grpc.WithChainUnaryInterceptor(retryRateLimited))...)
 	if err != nil {
 		fmt.Printf("Failed to connect: %v\n", err)
 		os.Exit(1)
@@ -43,25 +101,117 @@ func main() {
 
 	client := pb.NewFileServiceClient(conn)
 
-	command := os.Args[1]
+	command := args[0]
 
 	switch command {
 	case "upload":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: client upload <filepath>")
+		req, rest, err := uploadRequest(args[1:])
+		if err != nil || len(rest) < 1 {
+			fmt.Println("Usage: client upload [--ttl <duration> | --expires-at <time>] <filepath>")
+			os.Exit(1)
+		}
+		uploadFile(client, rest[0], req)
+
+	case "update":
+		req, rest, err := uploadRequest(args[1:])
+		if err != nil || len(rest) < 2 {
+			fmt.Println("Usage: client update [--ttl <duration> | --expires-at <time>] <file_id> <filepath>")
+			os.Exit(1)
+		}
+		req.FileId = rest[0]
+		uploadFile(client, rest[1], req)
+
+	case "versions":
+		if len(args) < 2 {
+			fmt.Println("Usage: client versions <file_id>")
 			os.Exit(1)
 		}
-		uploadFile(client, os.Args[2])
+		listVersions(client, args[1])
+
+	case "restore-version":
+		if len(args) < 3 {
+			fmt.Println("Usage: client restore-version <file_id> <version>")
+			os.Exit(1)
+		}
+		version, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid version: %s\n", args[2])
+			os.Exit(1)
+		}
+		restoreVersion(client, args[1], version)
+
+	case "resume":
+		if len(args) < 3 {
+			fmt.Println("Usage: client resume <session_id> <filepath>")
+			os.Exit(1)
+		}
+		resumeUpload(client, args[1], args[2])
 
 	case "download":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: client download <file_id> <output_path>")
+		if len(args) < 3 {
+			fmt.Println("Usage: client download <file_id> <output_path> [version]")
 			os.Exit(1)
 		}
-		downloadFile(client, os.Args[2], os.Args[3])
+		var version int64
+		if len(args) > 3 {
+			v, err := strconv.ParseInt(args[3], 10, 64)
+			if err != nil {
+				fmt.Printf("Invalid version: %s\n", args[3])
+				os.Exit(1)
+			}
+			version = v
+		}
+		downloadFile(client, args[1], args[2], version)
 
 	case "list":
-		listFile(client)
+		listFile(client, args[1:])
+
+	case "list-all":
+		listAll(client, args[1:])
+
+	case "info":
+		if len(args) < 2 {
+			fmt.Println("Usage: client info <file_id>")
+			os.Exit(1)
+		}
+		fileInfo(client, args[1])
+
+	case "delete":
+		if len(args) < 2 {
+			fmt.Println("Usage: client delete <file_id>")
+			os.Exit(1)
+		}
+		deleteFile(client, args[1])
+
+	case "trash":
+		listTrash(client)
+
+	case "restore":
+		if len(args) < 2 {
+			fmt.Println("Usage: client restore <file_id>")
+			os.Exit(1)
+		}
+		restoreFile(client, args[1])
+
+	case "usage":
+		showUsage(client)
+
+	case "namespaces":
+		listNamespaces(client)
+
+	case "create-namespace":
+		if len(args) != 2 && len(args) != 4 {
+			fmt.Println("Usage: client create-namespace <name> [<quota_bytes> <quota_files>]")
+			os.Exit(1)
+		}
+		createNamespace(client, args[1], args[2:])
+
+	case "delete-namespace":
+		if len(args) < 2 {
+			fmt.Println("Usage: client delete-namespace <name>")
+			os.Exit(1)
+		}
+		deleteNamespace(client, args[1])
 	
 	case "test-limits":
 		testRateLimits()
@@ -199,7 +349,7 @@ func testListLimit(sumReqs int) {
 			wgStart.Done()
 			wgStart.Wait()
 
-			_, err = client.List(ctx, &pb.ListRequest{})
+			_, err = client.List(ctx, &pb.ListRequest{Namespace: *namespace})
 
 			mu.Lock()
 			if err == nil {
@@ -240,10 +390,17 @@ func uploadFileQuiet(client pb.FileServiceClient, filePath string) error {
 		return err
 	}
 
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
 	err = stream.Send(&pb.UploadRequest{
 		Data: &pb.UploadRequest_Filename{
 			Filename: filepath.Base(filePath),
 		},
+		Size:      fileInfo.Size(),
+		Namespace: *namespace,
 	})
 	if err != nil {
 		_, recvErr := stream.CloseAndRecv()
@@ -282,10 +439,12 @@ func uploadFileQuiet(client pb.FileServiceClient, filePath string) error {
 }
 
 func newConn() (pb.FileServiceClient, *grpc.ClientConn, error) {
-	conn, err := grpc.NewClient(
-		serverAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	opts, err := dialOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := grpc.NewClient(serverAddr, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -294,7 +453,107 @@ func newConn() (pb.FileServiceClient, *grpc.ClientConn, error) {
 	return client, conn, nil
 }
 
-func uploadFile(client pb.FileServiceClient, path string) {
+func dialOptions() ([]grpc.DialOption, error) {
+	creds, err := transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+	}
+
+	if *apiKey != "" {
+		opts = append(opts,
+			grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+				return invoker(withAPIKey(ctx), method, req, reply, cc, callOpts...)
+			}),
+			grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return streamer(withAPIKey(ctx), desc, cc, method, callOpts...)
+			}),
+		)
+	}
+
+	return opts, nil
+}
+
+func transportCredentials() (credentials.TransportCredentials, error) {
+	if !*useTLS && *tlsCA == "" && *tlsCert == "" && *tlsKey == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if *tlsCA != "" {
+		pem, err := os.ReadFile(*tlsCA)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", *tlsCA)
+		}
+	}
+
+	if *tlsCert != "" || *tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+func withAPIKey(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", *apiKey)
+}
+
+// retryRateLimited repeats a call the server rejected over a rate limit once
+// the delay it suggested has passed. test-limits connects without it, as it
+// counts the rejections.
+func retryRateLimited(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	for attempt := 1; ; attempt++ {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		delay, ok := retryDelay(err)
+		if !ok || attempt == rateLimitAttempts {
+			return err
+		}
+
+		fmt.Printf("Server is busy, retrying in %s...\n", delay.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+			return err
+
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay reports how long to wait before trying a call again that was
+// rejected over a rate limit, as suggested by the server's RetryInfo.
+func retryDelay(err error) (time.Duration, bool) {
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+
+	return 0, false
+}
+
+// uploadFile uploads path as a new file, or as a new version of req.FileId
+// if it is set.
+func uploadFile(client pb.FileServiceClient, path string, req *pb.CreateUploadSessionRequest) {
 	file, err := os.Open(path)
 	if err != nil {
 		fmt.Printf("Failed to open file: %v\n", err)
@@ -308,82 +567,268 @@ func uploadFile(client pb.FileServiceClient, path string) {
 		return
 	}
 
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		fmt.Printf("Failed to read file: %v\n", err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
 	defer cancel()
 
-	stream, err := client.Upload(ctx)
+	req.Size = fileInfo.Size()
+	req.Namespace = *namespace
+	if req.FileId == "" {
+		// An update keeps the name the file already has.
+		req.Filename = filepath.Base(path)
+	}
+
+	// If the server already has this content, nothing needs to be sent.
+	resp, err := client.CreateFromDigest(ctx, &pb.CreateFromDigestRequest{
+		Checksum:  checksum,
+		Size:      req.Size,
+		Filename:  req.Filename,
+		Namespace: req.Namespace,
+		FileId:    req.FileId,
+		ExpiresAt: req.ExpiresAt,
+		Ttl:       req.Ttl,
+	})
+	if err == nil {
+		fmt.Println("Server already has this content, nothing to upload.")
+		fmt.Printf("File ID: %s\n", resp.Id)
+		fmt.Printf("Version: %d\n", resp.Version)
+		fmt.Printf("SHA-256: %s\n", resp.Checksum)
+		return
+	}
+	if !contentMissing(err) {
+		handleError(err, "upload")
+		return
+	}
+
+	session, err := client.CreateUploadSession(ctx, req)
 	if err != nil {
 		handleError(err, "upload")
 		return
 	}
 
-	err = stream.Send(&pb.UploadRequest{
-		Data: &pb.UploadRequest_Filename{
-			Filename: filepath.Base(path),
-		},
-	})
+	fmt.Printf("Upload session: %s\n", session.SessionId)
+
+	sendSession(client, file, checksum, session)
+}
+
+// contentMissing reports whether CreateFromDigest failed only because the
+// content has to be uploaded, either because the server does not have it or
+// because it is too old to know the call.
+func contentMissing(err error) bool {
+	st := status.Convert(err)
+
+	switch st.Code() {
+	case codes.NotFound:
+		return strings.HasPrefix(st.Message(), "content")
+
+	case codes.Unimplemented:
+		return true
+	}
+
+	return false
+}
+
+func resumeUpload(client pb.FileServiceClient, sessionID string, path string) {
+	file, err := os.Open(path)
 	if err != nil {
-		_, recvErr := stream.CloseAndRecv()
-		if recvErr != nil {
-			handleError(recvErr, "upload")
+		fmt.Printf("Failed to open file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	session, err := getSession(client, sessionID)
+	if err != nil {
+		handleError(err, "upload")
+		return
+	}
+
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		fmt.Printf("Failed to read file: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Resuming from byte %d\n", session.Offset)
+
+	sendSession(client, file, checksum, session)
+}
+
+// sendSession streams the file into an upload session and, when the
+// connection breaks, asks the server how much it has and continues from
+// there instead of starting over.
+func sendSession(client pb.FileServiceClient, file *os.File, checksum string, session *pb.UploadSession) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		fmt.Printf("Failed to get file info: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Uploading %s (%d bytes)...\n", filepath.Base(file.Name()), fileInfo.Size())
+
+	for attempt := 1; ; attempt++ {
+		if session.FileId == "" {
+			session, err = writeSession(client, file, fileInfo.Size(), checksum, session)
+		}
+		if err == nil {
+			break
+		}
+
+		if attempt == uploadAttempts || !retryable(err) {
+			fmt.Println()
+			handleError(err, "upload")
+			fmt.Printf("Resume with: client resume %s %s\n", session.SessionId, file.Name())
 			return
 		}
-		fmt.Printf("Failed to send file name: %v\n", err)
-		return
+
+		delay, ok := retryDelay(err)
+		if !ok {
+			delay = time.Duration(attempt) * time.Second
+		} else {
+			fmt.Printf("\nServer is busy, retrying in %s...\n", delay.Round(time.Millisecond))
+		}
+		time.Sleep(delay)
+
+		resumed, getErr := getSession(client, session.SessionId)
+		if getErr == nil {
+			session = resumed
+		}
+		err = getErr
 	}
 
-	buffer := make([]byte, chunkSize)
-	totalSent := 0
+	fmt.Println()
+	fmt.Printf("Upload successful!\n")
+	fmt.Printf("File ID: %s\n", session.FileId)
+	fmt.Printf("Version: %d\n", session.Version)
+	fmt.Printf("SHA-256: %s\n", session.Checksum)
+}
 
-	fmt.Printf("Uploading %s (%d bytes)...\n", filepath.Base(path), fileInfo.Size())
+func writeSession(client pb.FileServiceClient, file *os.File, size int64, checksum string, session *pb.UploadSession) (*pb.UploadSession, error) {
+	offset := session.Offset
+	if offset > size {
+		return session, fmt.Errorf("server has %d bytes but the file is only %d bytes", offset, size)
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return session, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	stream, err := client.WriteUploadSession(ctx)
+	if err != nil {
+		return session, err
+	}
+
+	buffer := make([]byte, chunkSize)
 
 	for {
 		n, err := file.Read(buffer)
-		
-		if err == io.EOF {
-			break
+		if err != nil && err != io.EOF {
+			return session, err
 		}
 
-		if err != nil {
-			fmt.Printf("Failed to read file: %v\n", err)
-			return
+		final := err == io.EOF
+
+		chunk := &pb.UploadSessionChunk{
+			SessionId: session.SessionId,
+			Offset:    offset,
+			Data:      buffer[:n],
+			Final:     final,
+		}
+		if final {
+			chunk.Checksum = checksum
 		}
 
-		err = stream.Send(&pb.UploadRequest{
-			Data: &pb.UploadRequest_Chunk{
-				Chunk: buffer[:n],
-			},
-		})
+		err = stream.Send(chunk)
 		if err != nil {
 			_, recvErr := stream.CloseAndRecv()
 			if recvErr != nil {
-				handleError(recvErr, "upload")
-				return
+				return session, recvErr
 			}
-			fmt.Printf("Failed to send chunk: %v\n", err)
-			return
+			return session, err
+		}
+
+		if final {
+			break
 		}
 
-		totalSent += n
-		progress := float64(totalSent) / float64(fileInfo.Size()) * 100
-		fmt.Printf("\rProgress: %.1f%%", progress)
+		offset += int64(n)
+		if size > 0 {
+			progress := float64(offset) / float64(size) * 100
+			fmt.Printf("\rProgress: %.1f%%", progress)
+		}
 	}
 
-	fmt.Println()
+	committed, err := stream.CloseAndRecv()
+	if err != nil {
+		return session, err
+	}
+
+	return committed, nil
+}
+
+// fileChecksum hashes the whole file and leaves the read position at the
+// start.
+func fileChecksum(file *os.File) (string, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
 
-	response, err := stream.CloseAndRecv()
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func localChecksum(path string) string {
+	file, err := os.Open(path)
 	if err != nil {
-		handleError(err, "upload")
-		return
+		return ""
 	}
+	defer file.Close()
 
-	fmt.Printf("Upload successful!\n")
-	fmt.Printf("File ID: %s\n", response.Id)
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		return ""
+	}
+
+	return checksum
 }
 
-func downloadFile(client pb.FileServiceClient, fileID string, outputPath string) {
-	var file *os.File
+func getSession(client pb.FileServiceClient, sessionID string) (*pb.UploadSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	return client.GetUploadSession(ctx, &pb.GetUploadSessionRequest{SessionId: sessionID})
+}
 
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+
+	default:
+		_, ok := retryDelay(err)
+		return ok
+	}
+}
+
+// downloadFile downloads the given version, 0 meaning the current one. It
+// continues an existing partial output file: it asks for the file size first
+// and only requests the bytes that are still missing.
+func downloadFile(client pb.FileServiceClient, fileID string, outputPath string, version int64) {
 	err := os.MkdirAll(outputPath, 0755)
 	if err != nil {
 		fmt.Printf("Failed to create directory: %v\n", err)
@@ -392,13 +837,63 @@ func downloadFile(client pb.FileServiceClient, fileID string, outputPath string)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
 	defer cancel()
-	
-	stream, err := client.Download(ctx, &pb.DownloadRequest{Id: fileID})
+
+	info, err := client.GetInfo(ctx, &pb.GetInfoRequest{Id: fileID, Namespace: *namespace, Version: version})
+	if err != nil {
+		handleError(err, "download")
+		return
+	}
+
+	path := filepath.Join(outputPath, filepath.Base(info.GetName()))
+
+	var offset int64
+	if stat, err := os.Stat(path); err == nil && stat.Size() <= info.GetSize() {
+		offset = stat.Size()
+	}
+
+	if offset == info.GetSize() && offset > 0 {
+		if localChecksum(path) == info.GetChecksum() {
+			fmt.Printf("%s is already downloaded\n", path)
+			return
+		}
+		offset = 0
+	}
+
+	flags := os.O_CREATE | os.O_RDWR | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_RDWR | os.O_APPEND
+		fmt.Printf("Resuming from byte %d\n", offset)
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		fmt.Printf("Failed to create file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	// Ask for the exact version GetInfo described, so an update uploaded in
+	// the meantime cannot mix into this download.
+	download := func(offset int64) (pb.FileService_DownloadClient, error) {
+		return client.Download(ctx, &pb.DownloadRequest{
+			Id:        fileID,
+			Offset:    offset,
+			Namespace: *namespace,
+			Version:   info.GetVersion(),
+		})
+	}
+
+	stream, err := download(offset)
 	if err != nil {
 		handleError(err, "download")
 		return
 	}
 
+	fmt.Printf("Downloading %s (%d bytes)...\n", info.GetName(), info.GetSize())
+
+	received := offset
+	attempt := 1
+
 	for {
 		resp, err := stream.Recv()
 		
@@ -406,60 +901,480 @@ func downloadFile(client pb.FileServiceClient, fileID string, outputPath string)
 			break
 		}
 
-		if err != nil {
-			if file != nil {
-				file.Close()
-				os.Remove(file.Name())
+		// A rejected stream is opened again where it stopped.
+		if delay, ok := retryDelay(err); ok && attempt < rateLimitAttempts {
+			attempt++
+			fmt.Printf("\nServer is busy, retrying in %s...\n", delay.Round(time.Millisecond))
+			time.Sleep(delay)
+
+			stream, err = download(received)
+			if err != nil {
+				handleError(err, "download")
+				return
 			}
+			continue
+		}
+
+		if err != nil {
+			fmt.Println()
 			handleError(err, "download")
+			fmt.Println("Run the same command again to resume.")
 			return
 		}
 
-		if file == nil {
-			file, err = os.Create(filepath.Join(outputPath, resp.GetInfo().GetName()))
-			if err != nil {
-				fmt.Printf("Failed to create file: %v\n", err)
-				return
-			}
-			defer file.Close()
+		if resp.GetInfo() != nil {
+			info = resp.GetInfo()
+			continue
+		}
+
+		n, err := file.Write(resp.GetChunk())
+		if err != nil {
+			fmt.Printf("Failed to write file: %v\n", err)
+			return
 		}
 
-		file.Write(resp.GetChunk())
+		received += int64(n)
+		if info.GetSize() > 0 {
+			progress := float64(received) / float64(info.GetSize()) * 100
+			fmt.Printf("\rProgress: %.1f%%", progress)
+		}
+	}
+
+	fmt.Println()
+
+	if received != info.GetSize() {
+		fmt.Printf("Download incomplete: have %d bytes, expected %d\n", received, info.GetSize())
+		fmt.Println("Run the same command again to resume.")
+		return
+	}
+
+	checksum, err := fileChecksum(file)
+	if err != nil {
+		fmt.Printf("Failed to verify file: %v\n", err)
+		return
+	}
+
+	if checksum != info.GetChecksum() {
+		os.Remove(path)
+		fmt.Printf("Download corrupted: SHA-256 is %s, expected %s\n", checksum, info.GetChecksum())
+		fmt.Println("The file was removed, run the same command again to download it from scratch.")
+		return
 	}
 
+	fmt.Printf("Received: %d bytes\n", received)
 	fmt.Println("Download successful!")
 }
 
-func listFile(client pb.FileServiceClient) {
+func fileInfo(client pb.FileServiceClient, fileID string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
 	defer cancel()
 
-	resp, err := client.List(ctx, &pb.ListRequest{})
+	info, err := client.GetInfo(ctx, &pb.GetInfoRequest{Id: fileID, Namespace: *namespace})
+	if err != nil {
+		handleError(err, "info")
+		return
+	}
+
+	fmt.Printf("ID: %s\n", info.GetId())
+	fmt.Printf("Namespace: %s\n", info.GetNamespace())
+	fmt.Printf("FileName: %s\n", info.GetName())
+	fmt.Printf("Size: %d bytes\n", info.GetSize())
+	fmt.Printf("Version: %d\n", info.GetVersion())
+	fmt.Printf("Content-Type: %s\n", info.GetContentType())
+	fmt.Printf("SHA-256: %s\n", info.GetChecksum())
+	fmt.Printf("Created_At: %v\n", info.GetCreatedAt().AsTime())
+	fmt.Printf("Updated_At: %v\n", info.GetUpdatedAt().AsTime())
+	if info.ExpiresAt != nil {
+		fmt.Printf("Expires_At: %v\n", info.GetExpiresAt().AsTime())
+	}
+}
+
+// listFile prints the whole listing, fetching it page by page.
+func listFile(client pb.FileServiceClient, args []string) {
+	req, err := listRequest(args)
+	if err != nil {
+		fmt.Printf("Invalid list options: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	printed := 0
+
+	for {
+		resp, err := client.List(ctx, req)
+		if err != nil {
+			handleError(err, "list")
+			return
+		}
+
+		if printed == 0 && len(resp.GetItems()) > 0 {
+			fmt.Println("List Files:")
+		}
+
+		for _, item := range resp.GetItems() {
+			printItem(item)
+			printed++
+		}
+
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	if printed == 0 {
+		fmt.Println("No files found.")
+	}
+}
+
+// listAll prints the listing as the server streams it, without paging.
+func listAll(client pb.FileServiceClient, args []string) {
+	req, err := listRequest(args)
+	if err != nil {
+		fmt.Printf("Invalid list options: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.ListAll(ctx, req)
 	if err != nil {
 		handleError(err, "list")
 		return
 	}
 
-	items := resp.GetItems()
+	printed := 0
+	attempt := 1
 
-	if len(items) == 0 {
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		// The limits reject a stream before it sends anything, so it can
+		// simply be opened again.
+		if delay, ok := retryDelay(err); ok && printed == 0 && attempt < rateLimitAttempts {
+			attempt++
+			fmt.Printf("Server is busy, retrying in %s...\n", delay.Round(time.Millisecond))
+			time.Sleep(delay)
+
+			stream, err = client.ListAll(ctx, req)
+			if err != nil {
+				handleError(err, "list")
+				return
+			}
+			continue
+		}
+
+		if err != nil {
+			handleError(err, "list")
+			return
+		}
+
+		if printed == 0 {
+			fmt.Println("List Files:")
+		}
+		printItem(item)
+		printed++
+	}
+
+	if printed == 0 {
 		fmt.Println("No files found.")
+	}
+}
+
+func printItem(item *pb.ListResponse_Item) {
+	expires := ""
+	if item.ExpiresAt != nil {
+		expires = fmt.Sprintf(" | Expires_At: %v", item.ExpiresAt.AsTime())
+	}
+
+	fmt.Printf(
+		"ID: %v | FileName: %v | Size: %v | Created_At: %v | Updated_At: %v%s\n",
+		item.Id,
+		item.Name,
+		item.Size,
+		item.CreatedAt.AsTime(),
+		item.UpdatedAt.AsTime(),
+		expires,
+	)
+}
+
+// uploadRequest parses the upload options in args and returns the
+// positional arguments that follow them.
+func uploadRequest(args []string) (*pb.CreateUploadSessionRequest, []string, error) {
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	ttl := fs.Duration("ttl", 0, "delete the file after this long")
+	expiresAt := fs.String("expires-at", "", "delete the file at this time (RFC 3339)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	req := &pb.CreateUploadSessionRequest{}
+
+	if *ttl != 0 {
+		req.Ttl = durationpb.New(*ttl)
+	}
+	if *expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, *expiresAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.ExpiresAt = timestamppb.New(t)
+	}
+
+	return req, fs.Args(), nil
+}
+
+func listRequest(args []string) (*pb.ListRequest, error) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	pageSize := fs.Int("page-size", 0, "items per request (server default if 0)")
+	order := fs.String("order", "created_at", "sort by created_at, name or size")
+	desc := fs.Bool("desc", false, "sort in descending order")
+	prefix := fs.String("prefix", "", "only names starting with prefix")
+	after := fs.String("after", "", "only files created after this time (RFC 3339)")
+	before := fs.String("before", "", "only files created before this time (RFC 3339)")
+	minSize := fs.Int64("min-size", 0, "minimum size in bytes")
+	maxSize := fs.Int64("max-size", 0, "maximum size in bytes")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	req := &pb.ListRequest{
+		Namespace:  *namespace,
+		PageSize:   int32(*pageSize),
+		Descending: *desc,
+		NamePrefix: *prefix,
+		MinSize:    *minSize,
+		MaxSize:    *maxSize,
+	}
+
+	switch *order {
+	case "created_at":
+		req.OrderBy = pb.ListOrder_LIST_ORDER_CREATED_AT
+	case "name":
+		req.OrderBy = pb.ListOrder_LIST_ORDER_NAME
+	case "size":
+		req.OrderBy = pb.ListOrder_LIST_ORDER_SIZE
+	default:
+		return nil, fmt.Errorf("unknown order %q", *order)
+	}
+
+	if *after != "" {
+		t, err := time.Parse(time.RFC3339, *after)
+		if err != nil {
+			return nil, err
+		}
+		req.CreatedAfter = timestamppb.New(t)
+	}
+	if *before != "" {
+		t, err := time.Parse(time.RFC3339, *before)
+		if err != nil {
+			return nil, err
+		}
+		req.CreatedBefore = timestamppb.New(t)
+	}
+
+	return req, nil
+}
+
+func deleteFile(client pb.FileServiceClient, fileID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	_, err := client.Delete(ctx, &pb.DeleteRequest{Id: fileID, Namespace: *namespace})
+	if err != nil {
+		handleError(err, "delete")
+		return
+	}
+
+	fmt.Println("Delete successful!")
+}
+
+func listTrash(client pb.FileServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	resp, err := client.ListTrash(ctx, &pb.ListTrashRequest{Namespace: *namespace})
+	if err != nil {
+		handleError(err, "trash")
+		return
+	}
+
+	fmt.Println("Trash:")
+
+	for _, file := range resp.GetFiles() {
+		fmt.Printf(
+			"ID: %v | FileName: %v | Size: %v | Deleted_At: %v | Purge_At: %v\n",
+			file.GetInfo().GetId(),
+			file.GetInfo().GetName(),
+			file.GetInfo().GetSize(),
+			file.GetDeletedAt().AsTime(),
+			file.GetPurgeAt().AsTime(),
+		)
+	}
+}
+
+func restoreFile(client pb.FileServiceClient, fileID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	info, err := client.Restore(ctx, &pb.RestoreRequest{Id: fileID, Namespace: *namespace})
+	if err != nil {
+		handleError(err, "restore")
+		return
+	}
+
+	fmt.Printf("Restored %s (%s)\n", info.GetName(), info.GetId())
+}
+
+func listVersions(client pb.FileServiceClient, fileID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	resp, err := client.ListVersions(ctx, &pb.ListVersionsRequest{Id: fileID, Namespace: *namespace})
+	if err != nil {
+		handleError(err, "versions")
 		return
 	}
 
-	fmt.Println("List Files:")
+	fmt.Println("Versions:")
+
+	for _, v := range resp.GetVersions() {
+		current := ""
+		if v.GetCurrent() {
+			current = " (current)"
+		}
 
-	for _, item := range items {
 		fmt.Printf(
-			"ID: %v | FileName: %v | Created_At: %v | Updated_At: %v\n",
-			item.Id,
-			item.Name,
-			item.CreatedAt.AsTime(),
-			item.UpdatedAt.AsTime(),
+			"Version: %v%s | Size: %v | SHA-256: %v | Created_At: %v\n",
+			v.GetVersion(),
+			current,
+			v.GetSize(),
+			v.GetChecksum(),
+			v.GetCreatedAt().AsTime(),
 		)
 	}
 }
 
+func restoreVersion(client pb.FileServiceClient, fileID string, version int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	info, err := client.RestoreVersion(ctx, &pb.RestoreVersionRequest{
+		Id:        fileID,
+		Namespace: *namespace,
+		Version:   version,
+	})
+	if err != nil {
+		handleError(err, "restore-version")
+		return
+	}
+
+	fmt.Printf("Restored version %d as version %d\n", version, info.GetVersion())
+}
+
+func showUsage(client pb.FileServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	usage, err := client.Usage(ctx, &pb.UsageRequest{Namespace: *namespace})
+	if err != nil {
+		handleError(err, "usage")
+		return
+	}
+
+	fmt.Printf("Namespace: %s\n", usage.GetNamespace())
+	fmt.Printf("Bytes: %d / %s\n", usage.GetUsedBytes(), quotaLimit(usage.GetQuotaBytes()))
+	fmt.Printf("Files: %d / %s\n", usage.GetUsedFiles(), quotaLimit(usage.GetQuotaFiles()))
+}
+
+func listNamespaces(client pb.FileServiceClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	resp, err := client.ListNamespaces(ctx, &pb.ListNamespacesRequest{})
+	if err != nil {
+		handleError(err, "namespaces")
+		return
+	}
+
+	fmt.Println("Namespaces:")
+
+	for _, ns := range resp.GetNamespaces() {
+		fmt.Printf(
+			"Name: %v | Files: %v / %v | Bytes: %v / %v | Created_At: %v\n",
+			ns.GetName(),
+			ns.GetUsedFiles(),
+			quotaLimit(ns.GetQuotaFiles()),
+			ns.GetUsedBytes(),
+			quotaLimit(ns.GetQuotaBytes()),
+			ns.GetCreatedAt().AsTime(),
+		)
+	}
+}
+
+// createNamespace creates a namespace, with its own quota when quota holds
+// the byte and file limits, or on the server default otherwise.
+func createNamespace(client pb.FileServiceClient, name string, quota []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	req := &pb.CreateNamespaceRequest{Name: name}
+
+	if len(quota) == 2 {
+		maxBytes, err := strconv.ParseInt(quota[0], 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid quota bytes: %s\n", quota[0])
+			return
+		}
+
+		maxFiles, err := strconv.ParseInt(quota[1], 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid quota files: %s\n", quota[1])
+			return
+		}
+
+		req.Quota = &pb.Quota{MaxBytes: maxBytes, MaxFiles: maxFiles}
+	}
+
+	ns, err := client.CreateNamespace(ctx, req)
+	if err != nil {
+		handleError(err, "create-namespace")
+		return
+	}
+
+	fmt.Printf("Namespace created: %s\n", ns.GetName())
+	fmt.Printf("Quota: %s bytes, %s files\n", quotaLimit(ns.GetQuotaBytes()), quotaLimit(ns.GetQuotaFiles()))
+}
+
+func deleteNamespace(client pb.FileServiceClient, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	_, err := client.DeleteNamespace(ctx, &pb.DeleteNamespaceRequest{Name: name})
+	if err != nil {
+		handleError(err, "delete-namespace")
+		return
+	}
+
+	fmt.Println("Namespace deleted!")
+}
+
+func quotaLimit(limit int64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+
+	return fmt.Sprint(limit)
+}
+
 func handleError(err error, operation string) {
 	st, ok := status.FromError(err)
 	
@@ -470,15 +1385,52 @@ func handleError(err error, operation string) {
 
 	switch st.Code() {
 	case codes.ResourceExhausted:
-		fmt.Printf("Rate limit exceeded: Too many concurrent %s requests.\n", operation)
+		if strings.HasPrefix(st.Message(), "quota exceeded") {
+			fmt.Printf("Quota exceeded: %s\n", st.Message())
+			return
+		}
+
+		if st.Message() == "request rate limit exceeded" {
+			fmt.Printf("Rate limit exceeded: Too many %s requests per second.\n", operation)
+		} else {
+			fmt.Printf("Rate limit exceeded: Too many concurrent %s requests.\n", operation)
+		}
+
+		for _, detail := range st.Details() {
+			if failure, ok := detail.(*errdetails.QuotaFailure); ok {
+				for _, v := range failure.GetViolations() {
+					fmt.Printf("Limit: %s (%s)\n", v.GetDescription(), v.GetSubject())
+				}
+			}
+		}
+
+		if delay, ok := retryDelay(err); ok {
+			fmt.Printf("Still busy after retrying, the server suggests trying again in %s.\n", delay.Round(time.Millisecond))
+			return
+		}
+
 		fmt.Println("Please try again in a few seconds.")
 		
 	case codes.NotFound:
+		if strings.HasPrefix(st.Message(), "namespace") {
+			fmt.Printf("Not found: %s\n", st.Message())
+			return
+		}
+
 		fmt.Printf("File not found: %s\n", st.Message())
+
+	case codes.AlreadyExists:
+		fmt.Printf("Already exists: %s\n", st.Message())
 		
 	case codes.InvalidArgument:
 		fmt.Printf("Invalid request: %s\n", st.Message())
 		
+	case codes.FailedPrecondition, codes.Aborted:
+		fmt.Printf("Request rejected: %s\n", st.Message())
+
+	case codes.DataLoss:
+		fmt.Printf("Data corrupted in transfer: %s\n", st.Message())
+
 	case codes.Internal:
 		fmt.Printf("Server error: %s\n", st.Message())
 		
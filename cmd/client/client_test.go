@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// rateLimited is a rejection suggesting to retry after delay.
+func rateLimited(t *testing.T, delay time.Duration) error {
+	t.Helper()
+
+	st, err := status.New(codes.ResourceExhausted, "request rate limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		t.Fatalf("WithDetails: %v", err)
+	}
+
+	return st.Err()
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		delay time.Duration
+		ok    bool
+	}{
+		{"retry info", rateLimited(t, 250*time.Millisecond), 250 * time.Millisecond, true},
+		{"no retry info", status.Error(codes.ResourceExhausted, "quota exceeded"), 0, false},
+		{"other code", status.Error(codes.Unavailable, "down"), 0, false},
+		{"no error", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, ok := retryDelay(tt.err)
+			if delay != tt.delay || ok != tt.ok {
+				t.Errorf("retryDelay() = %v, %v, want %v, %v", delay, ok, tt.delay, tt.ok)
+			}
+		})
+	}
+}
+
+// invoker fails the first calls with errs, then succeeds.
+func invoker(calls *int, errs ...error) grpc.UnaryInvoker {
+	return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	busy := rateLimited(t, time.Millisecond)
+	other := status.Error(codes.NotFound, "file not found")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"succeeds after retries", []error{busy, busy}, 3, nil},
+		{"other errors are not retried", []error{other}, 1, other},
+		{"gives up", []error{busy, busy, busy, busy, busy, busy}, rateLimitAttempts, busy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryRateLimited(context.Background(), "/fileservice.FileService/List", nil, nil, nil, invoker(&calls, tt.errs...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryRateLimitedCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	busy := rateLimited(t, time.Minute)
+
+	start := time.Now()
+	err := retryRateLimited(ctx, "/fileservice.FileService/List", nil, nil, nil, invoker(&calls, busy, busy))
+	if !errors.Is(err, busy) || calls != 1 {
+		t.Errorf("got %v after %d calls, want the rejection after 1", err, calls)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("waited %v past the deadline", took)
+	}
+}
@@ -1,42 +1,237 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
+	"maps"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	pb "github.com/YotoHana/tages-test-case/api/proto"
 	"github.com/YotoHana/tages-test-case/internal/api"
 	"github.com/YotoHana/tages-test-case/internal/semaphore"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
 const (
 	listenAddr = ":50051"
+
+	// compactInterval is how often the index journal is folded into
+	// index.json.
+	compactInterval = time.Minute
+	janitorInterval = time.Minute
+)
+
+// defaultMethodLimits keeps the streaming RPCs, which hold their slot for a
+// whole transfer, well below the limit of everything else.
+var defaultMethodLimits = methodLimits{
+	pb.FileService_Upload_FullMethodName:             {Concurrency: 10, Queue: -1, MaxWait: -1},
+	pb.FileService_Download_FullMethodName:           {Concurrency: 10, Queue: -1, MaxWait: -1},
+	pb.FileService_WriteUploadSession_FullMethodName: {Concurrency: 10, Queue: -1, MaxWait: -1},
+	pb.FileService_ListAll_FullMethodName:            {Concurrency: 10, Queue: -1, MaxWait: -1},
+}
+
+// Metrics are published by expvar at /debug/vars on the admin address.
+var (
+	expiredFiles   = expvar.NewInt("expired_files")
+	reclaimedBytes = expvar.NewInt("expired_bytes_reclaimed")
 )
 
 func main() {
+	backendKind := flag.String("storage", "fs", "storage backend: fs, memory or s3")
+	storageRoot := flag.String("storage-root", "./uploads", "root directory for the fs backend")
+	sessionDir := flag.String("session-dir", "./uploads/.sessions", "local directory for resumable upload sessions")
+	sessionTTL := flag.Duration("session-ttl", 24*time.Hour, "how long an idle upload session is kept")
+	adminAddr := flag.String("admin-addr", "", "address of the admin HTTP server serving metrics at /debug/vars, empty to disable")
+
+	var defaultLimit semaphore.Limit
+	flag.IntVar(&defaultLimit.Concurrency, "default-limit", 100, "concurrent calls allowed per method not listed in -method-limits, 0 for no limit")
+	flag.IntVar(&defaultLimit.Queue, "default-queue", 0, "calls per method that may wait for a free slot instead of being rejected, 0 rejects right away")
+	flag.DurationVar(&defaultLimit.MaxWait, "max-wait", 10*time.Second, "how long a queued call waits for a free slot, 0 waits up to the call's deadline")
+
+	var clientLimit semaphore.ClientLimit
+	flag.IntVar(&clientLimit.Concurrency, "client-limit", 0, "concurrent calls allowed per client over all methods, 0 for no limit")
+	clientRate := flag.Float64("client-rate", 0, "calls per second allowed per client, 0 for no limit")
+	flag.IntVar(&clientLimit.Burst, "client-burst", 0, "calls a client may make at once above -client-rate, 0 for the rate rounded down (at least 1)")
+	clientIdle := flag.Duration("client-idle", 10*time.Minute, "how long the limits of an idle client are kept")
+	apiKeysFile := flag.String("api-keys-file", "", "file with the API keys, one per line, that per-client limits trust; other callers are limited by address")
+
+	tlsCert := flag.String("tls-cert", "", "PEM certificate to serve TLS with, together with -tls-key; empty serves plaintext")
+	tlsKey := flag.String("tls-key", "", "PEM private key of -tls-cert")
+	tlsClientCA := flag.String("tls-client-ca", "", "PEM CA bundle client certificates are verified against; a verified certificate identifies its client to per-client limits")
+
+	// The flag fills a copy, so the built-in defaults stay as they are.
+	limits := maps.Clone(defaultMethodLimits)
+	flag.Var(limits, "method-limits", "admission per method as <method>=<limit>[:<queue>[:<max-wait>]], e.g. Upload=10:50:1m,List=200; a limit of 0 means no limit")
+
+	var apiConfig api.Config
+	flag.Int64Var(&apiConfig.MaxUploadSize, "max-upload-size", 100<<20, "maximum size of an uploaded file in bytes, 0 for no limit")
+	flag.Int64Var(&apiConfig.UploadRate, "upload-rate", 0, "bytes per second of all uploads together, 0 for no limit")
+	flag.Int64Var(&apiConfig.DownloadRate, "download-rate", 0, "bytes per second of all downloads together, 0 for no limit")
+	flag.Int64Var(&apiConfig.ClientUploadRate, "client-upload-rate", 0, "bytes per second of the uploads of a single client, 0 for no limit")
+	flag.Int64Var(&apiConfig.ClientDownloadRate, "client-download-rate", 0, "bytes per second of the downloads of a single client, 0 for no limit")
+
+	storeOptions := storage.Options{NamespaceQuotas: namespaceQuotas{}}
+	flag.Int64Var(&storeOptions.Quota.MaxBytes, "quota-bytes", 0, "maximum bytes stored per namespace, 0 for no limit")
+	flag.Int64Var(&storeOptions.Quota.MaxFiles, "quota-files", 0, "maximum number of files per namespace, 0 for no limit")
+	flag.Var(namespaceQuotas(storeOptions.NamespaceQuotas), "namespace-quotas", "comma-separated <namespace>=<bytes>:<files> quotas overriding -quota-bytes and -quota-files")
+	flag.IntVar(&storeOptions.MaxVersions, "max-versions", 10, "previous versions kept per file, 0 keeps all")
+	flag.DurationVar(&storeOptions.TrashRetention, "trash-retention", 7*24*time.Hour, "how long deleted files can be restored, 0 deletes right away")
+
+	var s3Config storage.S3Config
+	flag.StringVar(&s3Config.Endpoint, "s3-endpoint", "localhost:9000", "S3 endpoint (host:port)")
+	flag.StringVar(&s3Config.Bucket, "s3-bucket", "uploads", "S3 bucket name")
+	flag.StringVar(&s3Config.Prefix, "s3-prefix", "", "key prefix inside the S3 bucket")
+	flag.StringVar(&s3Config.Region, "s3-region", "", "S3 region")
+	flag.BoolVar(&s3Config.UseSSL, "s3-ssl", false, "use HTTPS for the S3 endpoint")
+	flag.StringVar(&s3Config.AccessKey, "s3-access-key", os.Getenv("S3_ACCESS_KEY"), "S3 access key (default $S3_ACCESS_KEY)")
+	flag.StringVar(&s3Config.SecretKey, "s3-secret-key", os.Getenv("S3_SECRET_KEY"), "S3 secret key (default $S3_SECRET_KEY)")
+	flag.Parse()
+
+	apiKeys, err := readAPIKeys(*apiKeysFile)
+	if err != nil {
+		log.Fatalf("failed to read API keys: %v", err)
+	}
+	identities := semaphore.NewIdentities(apiKeys)
+	apiConfig.Identities = identities
+
+	creds, err := serverCredentials(*tlsCert, *tlsKey, *tlsClientCA)
+	if err != nil {
+		log.Fatalf("failed to load TLS credentials: %v", err)
+	}
+
+	backend, err := newBackend(*backendKind, *storageRoot, s3Config)
+	if err != nil {
+		log.Fatalf("failed to init storage backend: %v", err)
+	}
+
+	store, err := storage.New(backend, storeOptions)
+	if err != nil {
+		log.Fatalf("failed to open storage: %v", err)
+	}
+
+	expvar.Publish("dedup", expvar.Func(func() any {
+		return store.DedupStats()
+	}))
+
+	sessions, err := storage.NewSessions(*sessionDir, store, *sessionTTL)
+	if err != nil {
+		log.Fatalf("failed to open upload sessions: %v", err)
+	}
+	for _, id := range sessions.Dropped() {
+		log.Printf("warning: dropped upload session %s: staged data is missing", id)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go every(ctx, compactInterval, func() {
+		if err := store.Compact(); err != nil {
+			log.Printf("failed to compact the index: %v", err)
+		}
+	})
+
+	go every(ctx, janitorInterval, func() {
+		n, err := sessions.Expire(time.Now())
+		if err != nil {
+			log.Printf("failed to expire upload sessions: %v", err)
+		}
+		if n > 0 {
+			log.Printf("expired %d upload sessions", n)
+		}
+	})
+
+	go every(ctx, janitorInterval, func() {
+		n, err := store.PurgeTrash(time.Now())
+		if err != nil {
+			log.Printf("failed to purge trash: %v", err)
+		}
+		if n > 0 {
+			log.Printf("purged %d deleted files", n)
+		}
+	})
+
+	go every(ctx, janitorInterval, func() {
+		n, size, err := store.Expire(time.Now())
+		if err != nil {
+			log.Printf("failed to delete expired files: %v", err)
+		}
+		if n > 0 {
+			expiredFiles.Add(int64(n))
+			reclaimedBytes.Add(size)
+			log.Printf("deleted %d expired files, reclaimed %d bytes", n, size)
+		}
+	})
+
+	var admin *http.Server
+	if *adminAddr != "" {
+		admin = &http.Server{Addr: *adminAddr}
+
+		go func() {
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("failed to serve admin HTTP: %v", err)
+			}
+		}()
+	}
+
 	lis, err := net.Listen("tcp", listenAddr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	streamLimiter := semaphore.NewSemaphore(10)
-	unaryLimiter := semaphore.NewSemaphore(100)
+	methods := semaphore.NewMethods(limits.withDefaults(defaultLimit), defaultLimit)
 
-	s := grpc.NewServer(
-		grpc.ChainStreamInterceptor(semaphore.RateLimitStream(streamLimiter)),
-		grpc.ChainUnaryInterceptor(semaphore.RateLimitUnary(unaryLimiter)),
-	)
-	fileServer, err := api.New()
-	if err != nil {
-		log.Fatalf("failed create new gRPC server: %v", err)
+	// Clients are checked first, so a client over its own limits does not
+	// take up a slot or a queue place of the shared per-method limits.
+	var streamInterceptors []grpc.StreamServerInterceptor
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+
+	clientLimit.Rate = rate.Limit(*clientRate)
+	if clientLimit.Concurrency > 0 || clientLimit.Rate > 0 {
+		clients := semaphore.NewClients(clientLimit, *clientIdle, identities)
+
+		expvar.Publish("rate_limited_clients", expvar.Func(func() any {
+			return clients.Len()
+		}))
+
+		go every(ctx, janitorInterval, func() {
+			clients.Evict(time.Now())
+		})
+
+		streamInterceptors = append(streamInterceptors, semaphore.ClientLimitStream(clients))
+		unaryInterceptors = append(unaryInterceptors, semaphore.ClientLimitUnary(clients))
+	}
+
+	streamInterceptors = append(streamInterceptors, semaphore.MethodLimitStream(methods))
+	unaryInterceptors = append(unaryInterceptors, semaphore.MethodLimitUnary(methods))
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+	}
+	if creds != nil {
+		serverOptions = append(serverOptions, grpc.Creds(creds))
 	}
-	pb.RegisterFileServiceServer(s, fileServer)
+
+	s := grpc.NewServer(serverOptions...)
+	pb.RegisterFileServiceServer(s, api.New(store, sessions, apiConfig))
 	reflection.Register(s)
 
 	sigChan := make(chan os.Signal, 1)
@@ -44,7 +239,10 @@ func main() {
 
 	go func() {
 		fmt.Printf("gRPC Server is running on port %s\n", listenAddr)
-		fmt.Println("Upload directory: ./uploads")
+		fmt.Printf("Storage backend: %s\n", *backendKind)
+		if *adminAddr != "" {
+			fmt.Printf("Metrics: http://%s/debug/vars\n", *adminAddr)
+		}
 		fmt.Println()
 		fmt.Println("Press Ctrl+C to stop...")
 		
@@ -59,7 +257,238 @@ func main() {
 	fmt.Println("Received shutdown signal...")
 	fmt.Println("Waiting for active requests to complete...")
 
+	cancel()
 	s.GracefulStop()
+	if admin != nil {
+		admin.Close()
+	}
+
+	if err := store.Compact(); err != nil {
+		log.Printf("failed to compact the index: %v", err)
+	}
 	
 	fmt.Println("Server stopped gracefully")
-}
\ No newline at end of file
+}
+
+func newBackend(kind, root string, s3Config storage.S3Config) (storage.Backend, error) {
+	switch kind {
+	case "fs":
+		return storage.NewFS(root)
+
+	case "memory":
+		return storage.NewMemory(), nil
+
+	case "s3":
+		return storage.NewS3(s3Config)
+
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", kind)
+	}
+}
+
+// serverCredentials loads the TLS setup of the server, nil for plaintext.
+// With a client CA, a client may present a certificate; it must then verify
+// and identifies the client (mTLS). Clients without one are still served and
+// told apart by API key or address.
+func serverCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, errors.New("-tls-client-ca needs -tls-cert and -tls-key")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// readAPIKeys reads one key per line, skipping blank lines and # comments.
+// An empty path means no keys.
+func readAPIKeys(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+
+	return keys, nil
+}
+
+func every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// namespaceQuotas is the -namespace-quotas flag. Every entry replaces the
+// default quota of one namespace; 0 still means no limit.
+type namespaceQuotas map[string]storage.Quota
+
+func (q namespaceQuotas) String() string {
+	var entries []string
+	for namespace, quota := range q {
+		entries = append(entries, fmt.Sprintf("%s=%d:%d", namespace, quota.MaxBytes, quota.MaxFiles))
+	}
+	sort.Strings(entries)
+
+	return strings.Join(entries, ",")
+}
+
+func (q namespaceQuotas) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		namespace, spec, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		bytes, files, ok := strings.Cut(spec, ":")
+		if !ok || namespace == "" {
+			return fmt.Errorf("invalid namespace quota %q, want <namespace>=<bytes>:<files>", entry)
+		}
+
+		var quota storage.Quota
+		var err error
+
+		quota.MaxBytes, err = strconv.ParseInt(bytes, 10, 64)
+		if err != nil || quota.MaxBytes < 0 {
+			return fmt.Errorf("invalid byte quota for namespace %s: %q", namespace, bytes)
+		}
+
+		quota.MaxFiles, err = strconv.ParseInt(files, 10, 64)
+		if err != nil || quota.MaxFiles < 0 {
+			return fmt.Errorf("invalid file quota for namespace %s: %q", namespace, files)
+		}
+
+		q[namespace] = quota
+	}
+
+	return nil
+}
+
+// methodLimits is the -method-limits flag. Methods may be given by their
+// full gRPC name or just by name, e.g. "Upload"; every entry overrides the
+// built-in limit of that method. A queue or max wait left out is taken from
+// -default-queue and -max-wait.
+type methodLimits map[string]semaphore.Limit
+
+func (l methodLimits) String() string {
+	var entries []string
+	for method, limit := range l {
+		method = strings.TrimPrefix(method, "/"+pb.FileService_ServiceDesc.ServiceName+"/")
+
+		entry := fmt.Sprintf("%s=%d", method, limit.Concurrency)
+		if limit.Queue >= 0 {
+			entry += fmt.Sprintf(":%d", limit.Queue)
+		}
+		if limit.MaxWait >= 0 {
+			entry += fmt.Sprintf(":%s", limit.MaxWait)
+		}
+
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+
+	return strings.Join(entries, ",")
+}
+
+func (l methodLimits) Set(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		method, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || method == "" {
+			return fmt.Errorf("invalid method limit %q, want <method>=<limit>[:<queue>[:<max-wait>]]", entry)
+		}
+
+		limit := semaphore.Limit{Queue: -1, MaxWait: -1}
+
+		fields := strings.Split(spec, ":")
+		if len(fields) > 3 {
+			return fmt.Errorf("invalid limit for method %s: %q", method, spec)
+		}
+
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid limit for method %s: %q", method, fields[0])
+		}
+		limit.Concurrency = n
+
+		if len(fields) > 1 {
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid queue for method %s: %q", method, fields[1])
+			}
+			limit.Queue = n
+		}
+
+		if len(fields) > 2 {
+			d, err := time.ParseDuration(fields[2])
+			if err != nil || d < 0 {
+				return fmt.Errorf("invalid max wait for method %s: %q", method, fields[2])
+			}
+			limit.MaxWait = d
+		}
+
+		if !strings.HasPrefix(method, "/") {
+			method = "/" + pb.FileService_ServiceDesc.ServiceName + "/" + method
+		}
+
+		l[method] = limit
+	}
+
+	return nil
+}
+
+// withDefaults fills in the queue and max wait of entries that left them out.
+func (l methodLimits) withDefaults(def semaphore.Limit) map[string]semaphore.Limit {
+	limits := make(map[string]semaphore.Limit, len(l))
+	for method, limit := range l {
+		if limit.Queue < 0 {
+			limit.Queue = def.Queue
+		}
+		if limit.MaxWait < 0 {
+			limit.MaxWait = def.MaxWait
+		}
+		limits[method] = limit
+	}
+
+	return limits
+}
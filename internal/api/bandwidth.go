@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/YotoHana/tages-test-case/internal/semaphore"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// throttle shapes the bytes of transfers in one direction to a rate shared
+// by everyone and a rate of each client, both in bytes per second. Clients
+// are told apart by identities and only tracked while one of their
+// transfers runs, so concurrent transfers of a client share its rate.
+type throttle struct {
+	global     *rate.Limiter
+	perClient  rate.Limit
+	identities *semaphore.Identities
+
+	mu      sync.Mutex
+	clients map[string]*clientBandwidth
+}
+
+type clientBandwidth struct {
+	limiter   *rate.Limiter
+	transfers int
+}
+
+// transfer is one stream's view of a throttle.
+type transfer struct {
+	ctx    context.Context
+	global *rate.Limiter
+	client *rate.Limiter
+	done   func()
+}
+
+// newThrottle returns a throttle for the given rates; 0 means no limit.
+func newThrottle(global, perClient int64, identities *semaphore.Identities) *throttle {
+	t := &throttle{
+		global:     rate.NewLimiter(rate.Inf, chunkSize),
+		identities: identities,
+		clients:    make(map[string]*clientBandwidth),
+	}
+
+	if global > 0 {
+		t.global = rate.NewLimiter(rate.Limit(global), chunkSize)
+	}
+	if perClient > 0 {
+		t.perClient = rate.Limit(perClient)
+	}
+
+	return t
+}
+
+// start begins a transfer of the caller of ctx. The caller must call done
+// once the transfer ends.
+func (t *throttle) start(ctx context.Context) *transfer {
+	tr := &transfer{ctx: ctx, global: t.global, done: func() {}}
+	if t.perClient == 0 {
+		return tr
+	}
+
+	key := t.identities.Of(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cl, ok := t.clients[key]
+	if !ok {
+		cl = &clientBandwidth{limiter: rate.NewLimiter(t.perClient, chunkSize)}
+		t.clients[key] = cl
+	}
+	cl.transfers++
+
+	tr.client = cl.limiter
+	tr.done = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		cl.transfers--
+		if cl.transfers == 0 {
+			delete(t.clients, key)
+		}
+	}
+
+	return tr
+}
+
+// wait blocks until n more bytes may be transferred.
+func (tr *transfer) wait(n int) error {
+	for n > 0 {
+		// WaitN never allows more than the burst at once.
+		step := min(n, chunkSize)
+
+		if tr.client != nil {
+			if err := tr.client.WaitN(tr.ctx, step); err != nil {
+				return tr.error(err)
+			}
+		}
+		if err := tr.global.WaitN(tr.ctx, step); err != nil {
+			return tr.error(err)
+		}
+
+		n -= step
+	}
+
+	return nil
+}
+
+func (tr *transfer) error(err error) error {
+	if tr.ctx.Err() != nil {
+		return status.FromContextError(tr.ctx.Err()).Err()
+	}
+
+	// The wait would run past the call's deadline.
+	return status.Errorf(codes.DeadlineExceeded, "transfer would not finish before the deadline: %v", err)
+}
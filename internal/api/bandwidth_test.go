@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// peerContext is the context of a call from addr.
+func peerContext(addr string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 50123},
+	})
+}
+
+func TestThrottleRate(t *testing.T) {
+	th := newThrottle(4*chunkSize, 0, nil)
+	tr := th.start(peerContext("10.0.0.1"))
+	defer tr.done()
+
+	// The first chunk is the burst, the other two take half a second.
+	start := time.Now()
+	if err := tr.wait(3 * chunkSize); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if took := time.Since(start); took < 400*time.Millisecond {
+		t.Errorf("3 chunks at 4 chunks/s took %v", took)
+	}
+}
+
+// Transfers of one client share its rate, and its state goes with its last
+// transfer.
+func TestThrottleClients(t *testing.T) {
+	th := newThrottle(0, chunkSize, nil)
+
+	a1 := th.start(peerContext("10.0.0.1"))
+	a2 := th.start(peerContext("10.0.0.1"))
+	b := th.start(peerContext("10.0.0.2"))
+
+	if a1.client != a2.client {
+		t.Error("transfers of one client got separate limiters")
+	}
+	if a1.client == b.client {
+		t.Error("two clients share a limiter")
+	}
+
+	a1.done()
+	if len(th.clients) != 2 {
+		t.Errorf("tracked %d clients with a transfer of each running, want 2", len(th.clients))
+	}
+	a2.done()
+	b.done()
+	if len(th.clients) != 0 {
+		t.Errorf("tracked %d clients after all transfers ended", len(th.clients))
+	}
+}
+
+func TestThrottleNoLimit(t *testing.T) {
+	th := newThrottle(0, 0, nil)
+	tr := th.start(peerContext("10.0.0.1"))
+	defer tr.done()
+
+	if tr.client != nil {
+		t.Error("transfer got a client limiter with no per-client rate")
+	}
+	if err := tr.wait(1000 * chunkSize); err != nil {
+		t.Errorf("wait: %v", err)
+	}
+}
+
+func TestThrottleDeadline(t *testing.T) {
+	th := newThrottle(0, chunkSize, nil)
+	ctx, cancel := context.WithTimeout(peerContext("10.0.0.1"), 100*time.Millisecond)
+	defer cancel()
+
+	tr := th.start(ctx)
+	defer tr.done()
+
+	if err := tr.wait(3 * chunkSize); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("wait past the deadline: got %v, want DeadlineExceeded", err)
+	}
+}
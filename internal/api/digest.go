@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+
+	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateFromDigest(ctx context.Context, req *pb.CreateFromDigestRequest) (*pb.UploadResponse, error) {
+	checksum := req.GetChecksum()
+	if digest, err := hex.DecodeString(checksum); err != nil || len(digest) != 32 {
+		return nil, status.Error(codes.InvalidArgument, "checksum must be a hex SHA-256")
+	}
+
+	fileID := req.GetFileId()
+	if req.GetFilename() == "" && fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename or file id is required")
+	}
+
+	if req.GetSize() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "size must be positive")
+	}
+	if s.tooLarge(req.GetSize()) {
+		return nil, s.sizeError()
+	}
+
+	expiresAt, err := expiry(req.GetExpiresAt(), req.GetTtl())
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := namespaceOf(req.GetNamespace())
+
+	meta, err := s.storage.CreateFromDigest(namespace, checksum, req.GetSize(), req.GetFilename(), fileID, expiresAt)
+	if err != nil {
+		if errors.Is(err, storage.ErrContentNotFound) {
+			return nil, status.Errorf(codes.NotFound, "content with sha-256 '%s' not found", checksum)
+		}
+		if err := admissionError(namespace, fileID, err); err != nil {
+			return nil, err
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to create file: %v", err)
+	}
+
+	return &pb.UploadResponse{
+		Id:       meta.ID,
+		Checksum: meta.Checksum,
+		Version:  meta.Version,
+	}, nil
+}
@@ -2,78 +2,233 @@ package api
 
 import (
 	"context"
+	"errors"
 	"io"
-	"os"
+	"time"
 
 	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/semaphore"
 	"github.com/YotoHana/tages-test-case/internal/storage"
-	"golang.org/x/time/rate"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	chunkSize = 64 * 1024
+
+	// defaultPageSize is used when ListRequest.page_size is 0; larger page
+	// sizes are capped at maxPageSize to keep responses well below the
+	// message size limit.
+	defaultPageSize = 100
+	maxPageSize     = 1000
 )
 
+// Config holds the per-server limits. Zero values mean "no limit".
+type Config struct {
+	// MaxUploadSize caps the size of a single uploaded file in bytes.
+	MaxUploadSize int64
+
+	// UploadRate and DownloadRate cap the bytes per second of all uploads
+	// and of all downloads together, ClientUploadRate and ClientDownloadRate
+	// those of a single client.
+	UploadRate         int64
+	DownloadRate       int64
+	ClientUploadRate   int64
+	ClientDownloadRate int64
+
+	// Identities tells clients apart for the per-client rates; nil keys them
+	// by address.
+	Identities *semaphore.Identities
+}
+
 type Server struct {
 	pb.UnimplementedFileServiceServer
-	storage *storage.Storage
+	storage  *storage.Storage
+	sessions *storage.Sessions
+	config   Config
 
-	uploadLimiter *rate.Limiter
-	listLimiter *rate.Limiter
+	uploads   *throttle
+	downloads *throttle
 }
-func (s *Server) List(ctx context.Context, _ *pb.ListRequest) (*pb.ListResponse, error) {
-	items, err := s.storage.GetFileList()
+
+func (s *Server) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	namespace := namespaceOf(req.GetNamespace())
+
+	opts, err := listOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	files, next, err := s.storage.ListFiles(namespace, opts)
 	if err != nil {
+		if errors.Is(err, storage.ErrNamespaceNotFound) {
+			return nil, status.Errorf(codes.NotFound, "namespace '%s' not found", namespace)
+		}
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+
 		return nil, status.Errorf(codes.Internal, "failed to read file directory: %v", err)
 	}
 
-	return &pb.ListResponse{Items: items}, nil
+	resp := &pb.ListResponse{Items: make([]*pb.ListResponse_Item, 0, len(files))}
+	for _, meta := range files {
+		resp.Items = append(resp.Items, meta.Item())
+	}
+	if next != nil {
+		resp.NextPageToken = next.Encode()
+	}
+
+	return resp, nil
 }
 
-func (s *Server) Upload(stream pb.FileService_UploadServer) error {
-	var id string
-	var file *os.File
+func (s *Server) ListAll(req *pb.ListRequest, stream pb.FileService_ListAllServer) error {
+	namespace := namespaceOf(req.GetNamespace())
 
-	defer func() {
-		if file != nil {
-			file.Close()
+	opts, err := listOptions(req)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.Walk(namespace, opts, func(meta *storage.FileMeta) error {
+		if err := stream.Send(meta.Item()); err != nil {
+			return status.Errorf(codes.Internal, "failed to send item: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrNamespaceNotFound) {
+			return status.Errorf(codes.NotFound, "namespace '%s' not found", namespace)
+		}
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			return status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		if _, ok := status.FromError(err); ok {
+			return err
 		}
-	}()
+
+		return status.Errorf(codes.Internal, "failed to read file directory: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Server) Upload(stream pb.FileService_UploadServer) error {
+	var file *storage.Writer
+	var checksum string
+	var declared int64
+	var namespace, fileID string
+
+	bandwidth := s.uploads.start(stream.Context())
+	defer bandwidth.done()
 
 	for {
 		req, err := stream.Recv()
 		if err == io.EOF {
-			return stream.SendAndClose(&pb.UploadResponse{Id: id})
+			if file == nil {
+				return status.Error(codes.InvalidArgument, "filename or file id is required")
+			}
+
+			if declared > 0 && file.Size() != declared {
+				file.Abort()
+				return status.Errorf(codes.InvalidArgument, "received %d bytes, declared size is %d", file.Size(), declared)
+			}
+
+			if err := file.Verify(checksum); err != nil {
+				file.Abort()
+				return status.Error(codes.DataLoss, err.Error())
+			}
+
+			if err := file.Commit(); err != nil {
+				if err := admissionError(namespace, fileID, err); err != nil {
+					return err
+				}
+
+				return status.Errorf(codes.Internal, "failed to save file: %v", err)
+			}
+
+			return stream.SendAndClose(&pb.UploadResponse{
+				Id:       file.ID(),
+				Checksum: file.Checksum(),
+				Version:  file.Version(),
+			})
 		}
 		if err != nil {
 			if file != nil {
-				os.Remove(file.Name())
+				file.Abort()
 			}
 			return status.Errorf(codes.Internal, "failed to receive data from client: %v", err)
 		}
 
 		if file == nil {
-			if req.GetFilename() == "" {
-				return status.Error(codes.InvalidArgument, "filename cannot be empty")
+			fileID = req.GetFileId()
+			if req.GetFilename() == "" && fileID == "" {
+				return status.Error(codes.InvalidArgument, "filename or file id is required")
 			}
 
-			file, id, err = s.storage.CreateFile(req.GetFilename())
+			declared = req.GetSize()
+			if declared < 0 {
+				return status.Error(codes.InvalidArgument, "size cannot be negative")
+			}
+			if s.tooLarge(declared) {
+				return s.sizeError()
+			}
 
+			expiresAt, err := expiry(req.GetExpiresAt(), req.GetTtl())
 			if err != nil {
+				return err
+			}
+
+			namespace = namespaceOf(req.GetNamespace())
+			if err := s.storage.CheckQuota(namespace, declared, fileID == ""); err != nil {
+				if err := admissionError(namespace, fileID, err); err != nil {
+					return err
+				}
+
+				return status.Errorf(codes.Internal, "failed to check quota: %v", err)
+			}
+
+			if fileID != "" {
+				file, err = s.storage.UpdateFile(namespace, fileID, req.GetFilename())
+			} else {
+				file, err = s.storage.CreateFile(namespace, req.GetFilename())
+			}
+
+			if err != nil {
+				if err := admissionError(namespace, fileID, err); err != nil {
+					return err
+				}
+
 				return status.Errorf(codes.Internal, "failed to create file: %v", err)
 			}
+			file.ExpireAt(expiresAt)
+		}
+
+		if req.GetChecksum() != "" {
+			checksum = req.GetChecksum()
+		}
+
+		if s.tooLarge(file.Size() + int64(len(req.GetChunk()))) {
+			file.Abort()
+			return s.sizeError()
+		}
+
+		if err := bandwidth.wait(len(req.GetChunk())); err != nil {
+			file.Abort()
+			return err
 		}
 
 		_, err = file.Write(req.GetChunk())
 		if err != nil {
-			os.Remove(file.Name())
+			file.Abort()
+			if err := admissionError(namespace, fileID, err); err != nil {
+				return err
+			}
 			return status.Errorf(codes.Internal, "incomplete write file")
 		}
 	}
-
-	
 }
 
 func (s *Server) Download(req *pb.DownloadRequest, stream pb.FileService_DownloadServer) error {
@@ -83,38 +238,77 @@ func (s *Server) Download(req *pb.DownloadRequest, stream pb.FileService_Downloa
 		return status.Error(codes.InvalidArgument, "id cannot be empty")
 	}
 
-	fullPath, originalName, err := s.storage.FindFileByID(fileID)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
-		}
+	offset, length := req.GetOffset(), req.GetLength()
+	if offset < 0 || length < 0 {
+		return status.Error(codes.InvalidArgument, "offset and length cannot be negative")
+	}
 
-		return status.Errorf(codes.Internal, "failed to locate file: %v", err)
+	if req.GetVersion() < 0 {
+		return status.Error(codes.InvalidArgument, "version cannot be negative")
 	}
 
-	file, err := os.Open(fullPath)
+	file, meta, err := s.storage.OpenVersion(namespaceOf(req.GetNamespace()), fileID, req.GetVersion())
 	if err != nil {
-		if os.IsNotExist(err) {
-			return status.Errorf(codes.NotFound, "file not found: %v", err)
+		if errors.Is(err, storage.ErrNotFound) {
+			return status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
+		}
+		if errors.Is(err, storage.ErrVersionNotFound) {
+			return status.Errorf(codes.NotFound, "version %d of file '%s' not found", req.GetVersion(), fileID)
 		}
 
 		return status.Errorf(codes.Internal, "failed to open file: %v", err)
 	}
 	defer file.Close()
 
+	if offset > meta.Size {
+		return status.Errorf(codes.OutOfRange, "offset %d is beyond file size %d", offset, meta.Size)
+	}
+	if length == 0 {
+		length = meta.Size - offset
+	}
+	if offset+length > meta.Size {
+		return status.Errorf(codes.OutOfRange, "range %d-%d is beyond file size %d", offset, offset+length, meta.Size)
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return status.Errorf(codes.Internal, "failed to seek file: %v", err)
+	}
+
 	err = stream.Send(&pb.DownloadResponse{
 		Payload: &pb.DownloadResponse_Info{
-			Info: &pb.FileInfo{Name: originalName},
+			Info: meta.Info(),
 		},
 	})
 	if err != nil {
 		return status.Errorf(codes.Internal, "Failed to send file metadata: %v", err)
 	}
 
+	reader := io.LimitReader(file, length)
 	buf := make([]byte, chunkSize)
 
+	bandwidth := s.downloads.start(stream.Context())
+	defer bandwidth.done()
+
 	for {
-		n, err := file.Read(buf)
+		n, err := reader.Read(buf)
+
+		// Backends may return the last bytes together with io.EOF, so the
+		// chunk has to be sent before the error is looked at.
+		if n > 0 {
+			if err := bandwidth.wait(n); err != nil {
+				return err
+			}
+
+			sendErr := stream.Send(&pb.DownloadResponse{
+				Payload: &pb.DownloadResponse_Chunk{
+					Chunk: buf[:n],
+				},
+			})
+			if sendErr != nil {
+				return status.Errorf(codes.Internal, "failed to send chunk: %v", sendErr)
+			}
+		}
+
 		if err == io.EOF {
 			break
 		}
@@ -122,30 +316,363 @@ func (s *Server) Download(req *pb.DownloadRequest, stream pb.FileService_Downloa
 		if err != nil {
 			return status.Errorf(codes.Internal, "failed to read file: %v", err)
 		}
+	}
 
-		if n > 0 {
-			stream.Send(&pb.DownloadResponse{
-				Payload: &pb.DownloadResponse_Chunk{
-					Chunk: buf[:n],
-				},
-			})
+	return nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	fileID := req.GetId()
+
+	if fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "id cannot be empty")
+	}
+
+	err := s.storage.Delete(namespaceOf(req.GetNamespace()), fileID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
 		}
+
+		return nil, status.Errorf(codes.Internal, "failed to delete file: %v", err)
 	}
 
-	return nil
+	return &pb.DeleteResponse{}, nil
 }
 
-func New() (*Server, error) {
-	storage, err := storage.New()
+func (s *Server) GetInfo(ctx context.Context, req *pb.GetInfoRequest) (*pb.FileInfo, error) {
+	fileID := req.GetId()
+
+	if fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "id cannot be empty")
+	}
+
+	meta, err := s.storage.FindVersion(namespaceOf(req.GetNamespace()), fileID, req.GetVersion())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
+		}
+		if errors.Is(err, storage.ErrVersionNotFound) {
+			return nil, status.Errorf(codes.NotFound, "version %d of file '%s' not found", req.GetVersion(), fileID)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to locate file: %v", err)
+	}
+
+	return meta.Info(), nil
+}
+
+func (s *Server) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.UploadSession, error) {
+	fileID := req.GetFileId()
+	if req.GetFilename() == "" && fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename or file id is required")
+	}
+
+	if req.GetSize() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "size cannot be negative")
+	}
+	if s.tooLarge(req.GetSize()) {
+		return nil, s.sizeError()
+	}
+
+	expiresAt, err := expiry(req.GetExpiresAt(), req.GetTtl())
 	if err != nil {
 		return nil, err
 	}
-	
-	server := &Server{
-		storage: storage,
-		listLimiter: rate.NewLimiter(rate.Inf, 100),
-		uploadLimiter: rate.NewLimiter(rate.Inf, 10),
+
+	namespace := namespaceOf(req.GetNamespace())
+	if fileID != "" {
+		if _, err := s.storage.FindFileByID(namespace, fileID); err != nil {
+			if err := admissionError(namespace, fileID, err); err != nil {
+				return nil, err
+			}
+
+			return nil, status.Errorf(codes.Internal, "failed to locate file: %v", err)
+		}
+	}
+	if err := s.storage.CheckQuota(namespace, req.GetSize(), fileID == ""); err != nil {
+		if err := admissionError(namespace, fileID, err); err != nil {
+			return nil, err
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to check quota: %v", err)
+	}
+
+	session, err := s.sessions.Create(namespace, req.GetFilename(), fileID, expiresAt)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create upload session: %v", err)
+	}
+
+	return s.sessionInfo(session), nil
+}
+
+func (s *Server) GetUploadSession(ctx context.Context, req *pb.GetUploadSessionRequest) (*pb.UploadSession, error) {
+	sessionID := req.GetSessionId()
+
+	if sessionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id cannot be empty")
+	}
+
+	session, err := s.sessions.Get(sessionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "upload session '%s' not found", sessionID)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to load upload session: %v", err)
 	}
 
-	return server, nil
-}
\ No newline at end of file
+	return s.sessionInfo(session), nil
+}
+
+func (s *Server) WriteUploadSession(stream pb.FileService_WriteUploadSessionServer) error {
+	var writer *storage.SessionWriter
+	var namespace, fileID string
+
+	bandwidth := s.uploads.start(stream.Context())
+	defer bandwidth.done()
+
+	defer func() {
+		if writer != nil {
+			writer.Close()
+		}
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if writer == nil {
+				return status.Error(codes.InvalidArgument, "session id cannot be empty")
+			}
+
+			return stream.SendAndClose(s.sessionInfo(writer.Session()))
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to receive data from client: %v", err)
+		}
+
+		if writer == nil {
+			sessionID := req.GetSessionId()
+			if sessionID == "" {
+				return status.Error(codes.InvalidArgument, "session id cannot be empty")
+			}
+
+			writer, err = s.sessions.Open(sessionID)
+			if err != nil {
+				return sessionError(sessionID, err)
+			}
+			namespace = writer.Session().Namespace
+			fileID = writer.Session().Target
+		}
+
+		if s.tooLarge(req.GetOffset() + int64(len(req.GetData()))) {
+			writer.Abort()
+			return s.sizeError()
+		}
+		if err := s.storage.CheckQuota(namespace, req.GetOffset()+int64(len(req.GetData())), fileID == ""); err != nil {
+			if err := admissionError(namespace, fileID, err); err != nil {
+				return err
+			}
+
+			return status.Errorf(codes.Internal, "failed to check quota: %v", err)
+		}
+
+		if err := bandwidth.wait(len(req.GetData())); err != nil {
+			return err
+		}
+
+		err = writer.WriteAt(req.GetData(), req.GetOffset())
+		if err != nil {
+			var mismatch *storage.OffsetMismatchError
+			if errors.As(err, &mismatch) {
+				return status.Error(codes.Aborted, mismatch.Error())
+			}
+
+			return status.Errorf(codes.Internal, "incomplete write file")
+		}
+
+		if req.GetFinal() {
+			if _, err := writer.Commit(req.GetChecksum()); err != nil {
+				if errors.Is(err, storage.ErrChecksumMismatch) {
+					return status.Error(codes.DataLoss, err.Error())
+				}
+				if err := admissionError(namespace, fileID, err); err != nil {
+					return err
+				}
+
+				return status.Errorf(codes.Internal, "failed to save file: %v", err)
+			}
+
+			return stream.SendAndClose(s.sessionInfo(writer.Session()))
+		}
+	}
+}
+
+func (s *Server) Usage(ctx context.Context, req *pb.UsageRequest) (*pb.UsageResponse, error) {
+	namespace := namespaceOf(req.GetNamespace())
+
+	usage, quota, err := s.storage.Usage(namespace)
+	if err != nil {
+		if errors.Is(err, storage.ErrNamespaceNotFound) {
+			return nil, status.Errorf(codes.NotFound, "namespace '%s' not found", namespace)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to read usage: %v", err)
+	}
+
+	return &pb.UsageResponse{
+		Namespace:  namespace,
+		UsedBytes:  usage.Bytes,
+		UsedFiles:  usage.Files,
+		QuotaBytes: quota.MaxBytes,
+		QuotaFiles: quota.MaxFiles,
+	}, nil
+}
+
+func listOptions(req *pb.ListRequest) (storage.ListOptions, error) {
+	opts := storage.ListOptions{
+		Desc:  req.GetDescending(),
+		Limit: defaultPageSize,
+		Filter: storage.ListFilter{
+			NamePrefix: req.GetNamePrefix(),
+			MinSize:    req.GetMinSize(),
+			MaxSize:    req.GetMaxSize(),
+		},
+	}
+
+	switch req.GetOrderBy() {
+	case pb.ListOrder_LIST_ORDER_CREATED_AT:
+		opts.Order = storage.OrderCreatedAt
+	case pb.ListOrder_LIST_ORDER_NAME:
+		opts.Order = storage.OrderName
+	case pb.ListOrder_LIST_ORDER_SIZE:
+		opts.Order = storage.OrderSize
+	default:
+		return opts, status.Errorf(codes.InvalidArgument, "unknown order %v", req.GetOrderBy())
+	}
+
+	if req.GetPageSize() < 0 {
+		return opts, status.Error(codes.InvalidArgument, "page size cannot be negative")
+	}
+	if req.GetPageSize() > 0 {
+		opts.Limit = min(int(req.GetPageSize()), maxPageSize)
+	}
+
+	if req.GetMinSize() < 0 || req.GetMaxSize() < 0 {
+		return opts, status.Error(codes.InvalidArgument, "size filters cannot be negative")
+	}
+
+	if req.CreatedAfter != nil {
+		opts.Filter.CreatedAfter = req.GetCreatedAfter().AsTime()
+	}
+	if req.CreatedBefore != nil {
+		opts.Filter.CreatedBefore = req.GetCreatedBefore().AsTime()
+	}
+
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := storage.DecodeCursor(token)
+		if err != nil {
+			return opts, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		opts.After = cursor
+	}
+
+	return opts, nil
+}
+
+func (s *Server) sessionInfo(session *storage.Session) *pb.UploadSession {
+	return &pb.UploadSession{
+		SessionId: session.ID,
+		Filename:  session.Name,
+		Offset:    session.Offset,
+		ExpiresAt: timestamppb.New(s.sessions.ExpiresAt(session)),
+		FileId:    session.FileID,
+		Checksum:  session.Checksum,
+		Namespace: session.Namespace,
+		Version:   session.Version,
+	}
+}
+
+func sessionError(sessionID string, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return status.Errorf(codes.NotFound, "upload session '%s' not found", sessionID)
+
+	case errors.Is(err, storage.ErrSessionBusy):
+		return status.Error(codes.Aborted, err.Error())
+
+	case errors.Is(err, storage.ErrSessionCommitted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+
+	default:
+		return status.Errorf(codes.Internal, "failed to open upload session: %v", err)
+	}
+}
+
+// admissionError maps the reasons storage refuses to take a new file, or a
+// new version of fileID, to a status. It returns nil for any other error.
+func admissionError(namespace, fileID string, err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
+
+	case errors.Is(err, storage.ErrNamespaceNotFound):
+		return status.Errorf(codes.NotFound, "namespace '%s' not found", namespace)
+
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return nil
+}
+
+// expiry turns the optional expires_at or ttl of an upload into the time the
+// file expires, zero if neither is set.
+func expiry(expiresAt *timestamppb.Timestamp, ttl *durationpb.Duration) (time.Time, error) {
+	switch {
+	case expiresAt != nil && ttl != nil:
+		return time.Time{}, status.Error(codes.InvalidArgument, "expires_at and ttl cannot both be set")
+
+	case ttl != nil:
+		if err := ttl.CheckValid(); err != nil || ttl.AsDuration() <= 0 {
+			return time.Time{}, status.Error(codes.InvalidArgument, "ttl must be positive")
+		}
+
+		return time.Now().Add(ttl.AsDuration()), nil
+
+	case expiresAt != nil:
+		if err := expiresAt.CheckValid(); err != nil || !expiresAt.AsTime().After(time.Now()) {
+			return time.Time{}, status.Error(codes.InvalidArgument, "expires_at must be in the future")
+		}
+
+		return expiresAt.AsTime(), nil
+	}
+
+	return time.Time{}, nil
+}
+
+func namespaceOf(name string) string {
+	if name == "" {
+		return storage.DefaultNamespace
+	}
+
+	return name
+}
+
+func (s *Server) tooLarge(size int64) bool {
+	return s.config.MaxUploadSize > 0 && size > s.config.MaxUploadSize
+}
+
+func (s *Server) sizeError() error {
+	return status.Errorf(codes.InvalidArgument, "file size exceeds maximum allowed size of %d bytes", s.config.MaxUploadSize)
+}
+
+func New(storage *storage.Storage, sessions *storage.Sessions, config Config) *Server {
+	return &Server{
+		storage:   storage,
+		sessions:  sessions,
+		config:    config,
+		uploads:   newThrottle(config.UploadRate, config.ClientUploadRate, config.Identities),
+		downloads: newThrottle(config.DownloadRate, config.ClientDownloadRate, config.Identities),
+	}
+}
@@ -0,0 +1,677 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestClient serves a Server with the default config over an in-memory
+// connection.
+func newTestClient(t *testing.T) pb.FileServiceClient {
+	t.Helper()
+
+	return newConfiguredClient(t, Config{})
+}
+
+func newConfiguredClient(t *testing.T, config Config) pb.FileServiceClient {
+	t.Helper()
+
+	return newStorageClient(t, storage.Options{}, config)
+}
+
+// newStorageClient is newConfiguredClient for a store opened with opts.
+func newStorageClient(t *testing.T, opts storage.Options, config Config) pb.FileServiceClient {
+	t.Helper()
+
+	store, err := storage.New(storage.NewMemory(), opts)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+
+	sessions, err := storage.NewSessions(t.TempDir(), store, time.Hour)
+	if err != nil {
+		t.Fatalf("storage.NewSessions: %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pb.RegisterFileServiceServer(grpcServer, New(store, sessions, config))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewFileServiceClient(conn)
+}
+
+func uploadFile(t *testing.T, client pb.FileServiceClient, name, content string) string {
+	t.Helper()
+
+	resp, err := uploadChecked(client, name, content, "")
+	if err != nil {
+		t.Fatalf("Upload(%s): %v", name, err)
+	}
+
+	return resp.GetId()
+}
+
+// uploadChecked uploads content, asking the server to verify checksum.
+func uploadChecked(client pb.FileServiceClient, name, content, checksum string) (*pb.UploadResponse, error) {
+	stream, err := client.Upload(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, req := range []*pb.UploadRequest{
+		{Data: &pb.UploadRequest_Filename{Filename: name}},
+		{Data: &pb.UploadRequest_Chunk{Chunk: []byte(content)}, Checksum: checksum},
+	} {
+		if err := stream.Send(req); err != nil {
+			break
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// download returns the file's content, or the status code of the failure.
+func download(t *testing.T, client pb.FileServiceClient, req *pb.DownloadRequest) (string, codes.Code) {
+	t.Helper()
+
+	stream, err := client.Download(context.Background(), req)
+	if err != nil {
+		return "", status.Code(err)
+	}
+
+	var content []byte
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return string(content), codes.OK
+		}
+		if err != nil {
+			return "", status.Code(err)
+		}
+		content = append(content, resp.GetChunk()...)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	client := newTestClient(t)
+	id := uploadFile(t, client, "a.txt", "hello")
+	kept := uploadFile(t, client, "b.txt", "world")
+
+	tests := []struct {
+		name string
+		id   string
+		want codes.Code
+	}{
+		{"existing", id, codes.OK},
+		{"already deleted", id, codes.NotFound},
+		{"unknown", "no-such-id", codes.NotFound},
+		{"empty id", "", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Delete(context.Background(), &pb.DeleteRequest{Id: tt.id})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Delete(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	if _, code := download(t, client, &pb.DownloadRequest{Id: id}); code != codes.NotFound {
+		t.Errorf("Download of deleted file = %v, want NotFound", code)
+	}
+	if content, code := download(t, client, &pb.DownloadRequest{Id: kept}); code != codes.OK || content != "world" {
+		t.Errorf("Download of other file = %q, %v, want %q", content, code, "world")
+	}
+}
+
+func TestGetInfo(t *testing.T) {
+	client := newTestClient(t)
+	id := uploadFile(t, client, "a.txt", "hello")
+
+	info, err := client.GetInfo(context.Background(), &pb.GetInfoRequest{Id: id})
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+
+	if info.GetId() != id || info.GetName() != "a.txt" || info.GetSize() != 5 ||
+		info.GetChecksum() != checksum("hello") ||
+		!strings.HasPrefix(info.GetContentType(), "text/plain") {
+		t.Errorf("GetInfo = %v", info)
+	}
+	if info.GetCreatedAt() == nil || info.GetUpdatedAt() == nil {
+		t.Errorf("GetInfo timestamps = %v, %v, want both set", info.GetCreatedAt(), info.GetUpdatedAt())
+	}
+
+	for _, tt := range []struct {
+		id   string
+		want codes.Code
+	}{
+		{"no-such-id", codes.NotFound},
+		{"", codes.InvalidArgument},
+	} {
+		if _, err := client.GetInfo(context.Background(), &pb.GetInfoRequest{Id: tt.id}); status.Code(err) != tt.want {
+			t.Errorf("GetInfo(%q) = %v, want %v", tt.id, err, tt.want)
+		}
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	client := newTestClient(t)
+	id := uploadFile(t, client, "a.txt", "hello world")
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           string
+		code           codes.Code
+	}{
+		{"whole file", 0, 0, "hello world", codes.OK},
+		{"from offset", 6, 0, "world", codes.OK},
+		{"range", 2, 3, "llo", codes.OK},
+		{"up to the end", 6, 5, "world", codes.OK},
+		{"empty tail", 11, 0, "", codes.OK},
+		{"offset beyond the end", 12, 0, "", codes.OutOfRange},
+		{"range beyond the end", 6, 6, "", codes.OutOfRange},
+		{"negative offset", -1, 0, "", codes.InvalidArgument},
+		{"negative length", 0, -1, "", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code := download(t, client, &pb.DownloadRequest{Id: id, Offset: tt.offset, Length: tt.length})
+			if code != tt.code || got != tt.want {
+				t.Errorf("Download(%d, %d) = %q, %v, want %q, %v", tt.offset, tt.length, got, code, tt.want, tt.code)
+			}
+		})
+	}
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		checksum string
+		want     codes.Code
+	}{
+		{"none", "", codes.OK},
+		{"matching", checksum("hello"), codes.OK},
+		{"upper case", strings.ToUpper(checksum("hello")), codes.OK},
+		{"different", checksum("hello!"), codes.DataLoss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t)
+
+			resp, err := uploadChecked(client, "a.txt", "hello", tt.checksum)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("Upload = %v, want %v", err, tt.want)
+			}
+
+			list, err := client.List(context.Background(), &pb.ListRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != codes.OK {
+				if len(list.GetItems()) != 0 {
+					t.Errorf("files after a rejected upload = %v, want none", list.GetItems())
+				}
+				return
+			}
+
+			if resp.GetChecksum() != checksum("hello") {
+				t.Errorf("checksum = %q, want %q", resp.GetChecksum(), checksum("hello"))
+			}
+		})
+	}
+}
+
+func TestUploadSessionChecksum(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	session, err := client.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{Filename: "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(chunk *pb.UploadSessionChunk) (*pb.UploadSession, error) {
+		stream, err := client.WriteUploadSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stream.Send(chunk)
+		return stream.CloseAndRecv()
+	}
+
+	_, err = write(&pb.UploadSessionChunk{
+		SessionId: session.GetSessionId(),
+		Data:      []byte("hello"),
+		Final:     true,
+		Checksum:  checksum("hello!"),
+	})
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("final chunk with a different checksum = %v, want DataLoss", err)
+	}
+
+	// The staged data is kept, so the client can finish once it knows
+	// what it sent.
+	done, err := write(&pb.UploadSessionChunk{
+		SessionId: session.GetSessionId(),
+		Offset:    5,
+		Final:     true,
+		Checksum:  checksum("hello"),
+	})
+	if err != nil {
+		t.Fatalf("final chunk with the matching checksum: %v", err)
+	}
+	if done.GetFileId() == "" || done.GetChecksum() != checksum("hello") {
+		t.Errorf("session = %v, want a file id and checksum %s", done, checksum("hello"))
+	}
+	if got, code := download(t, client, &pb.DownloadRequest{Id: done.GetFileId()}); code != codes.OK || got != "hello" {
+		t.Errorf("Download = %q, %v, want %q", got, code, "hello")
+	}
+}
+
+func TestMaxUploadSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared int64
+		content  string
+		want     codes.Code
+	}{
+		{"at the limit", 0, "hello", codes.OK},
+		{"declared at the limit", 5, "hello", codes.OK},
+		{"over the limit", 0, "hello!", codes.InvalidArgument},
+		{"declared over the limit", 6, "", codes.InvalidArgument},
+		{"less than declared", 5, "hell", codes.InvalidArgument},
+		{"negative size", -1, "hello", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newConfiguredClient(t, Config{MaxUploadSize: 5})
+
+			stream, err := client.Upload(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream.Send(&pb.UploadRequest{Data: &pb.UploadRequest_Filename{Filename: "a.txt"}, Size: tt.declared})
+			stream.Send(&pb.UploadRequest{Data: &pb.UploadRequest_Chunk{Chunk: []byte(tt.content)}})
+			_, err = stream.CloseAndRecv()
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("Upload = %v, want %v", err, tt.want)
+			}
+
+			list, err := client.List(context.Background(), &pb.ListRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want == codes.OK; (len(list.GetItems()) == 1) != want {
+				t.Errorf("files = %v, want stored = %v", list.GetItems(), want)
+			}
+		})
+	}
+}
+
+func TestMaxUploadSizeSession(t *testing.T) {
+	client := newConfiguredClient(t, Config{MaxUploadSize: 5})
+	ctx := context.Background()
+
+	_, err := client.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{Filename: "a.txt", Size: 6})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateUploadSession over the limit = %v, want InvalidArgument", err)
+	}
+
+	session, err := client.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{Filename: "a.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := client.WriteUploadSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&pb.UploadSessionChunk{SessionId: session.GetSessionId(), Data: []byte("hel")})
+	stream.Send(&pb.UploadSessionChunk{SessionId: session.GetSessionId(), Offset: 3, Data: []byte("lo!")})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("WriteUploadSession over the limit = %v, want InvalidArgument", err)
+	}
+
+	// The session is dropped together with what was staged.
+	_, err = client.GetUploadSession(ctx, &pb.GetUploadSessionRequest{SessionId: session.GetSessionId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetUploadSession after exceeding the limit = %v, want NotFound", err)
+	}
+}
+
+func TestCreateNamespaceQuota(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	ns, err := client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{
+		Name:  "team-a",
+		Quota: &pb.Quota{MaxBytes: 5, MaxFiles: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.GetQuotaBytes() != 5 || ns.GetQuotaFiles() != 2 {
+		t.Errorf("created namespace quota = %d bytes, %d files, want 5, 2", ns.GetQuotaBytes(), ns.GetQuotaFiles())
+	}
+
+	if _, err := client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "team-b"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "team-c", Quota: &pb.Quota{MaxBytes: -1}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateNamespace with a negative quota = %v, want InvalidArgument", err)
+	}
+
+	for _, tt := range []struct {
+		namespace string
+		bytes     int64
+	}{
+		{"team-a", 5},
+		{"team-b", 0},
+	} {
+		usage, err := client.Usage(ctx, &pb.UsageRequest{Namespace: tt.namespace})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage.GetQuotaBytes() != tt.bytes {
+			t.Errorf("Usage(%s) quota = %d bytes, want %d", tt.namespace, usage.GetQuotaBytes(), tt.bytes)
+		}
+	}
+
+	stream, err := client.Upload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&pb.UploadRequest{Data: &pb.UploadRequest_Filename{Filename: "a.txt"}, Namespace: "team-a"})
+	stream.Send(&pb.UploadRequest{Data: &pb.UploadRequest_Chunk{Chunk: []byte("hello!")}})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Upload over the namespace quota = %v, want ResourceExhausted", err)
+	}
+}
+
+func TestListAll(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	var want []string
+	for i := range 5 {
+		name := fmt.Sprintf("f%d.txt", i)
+		uploadFile(t, client, name, name)
+		want = append(want, name)
+	}
+
+	listAll := func(req *pb.ListRequest) []string {
+		stream, err := client.ListAll(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for {
+			item, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return names
+			}
+			if err != nil {
+				t.Fatalf("ListAll: %v", err)
+			}
+			names = append(names, item.GetName())
+		}
+	}
+
+	if got := listAll(&pb.ListRequest{OrderBy: pb.ListOrder_LIST_ORDER_NAME}); !slices.Equal(got, want) {
+		t.Errorf("ListAll = %v, want %v", got, want)
+	}
+
+	page, err := client.List(ctx, &pb.ListRequest{OrderBy: pb.ListOrder_LIST_ORDER_NAME, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := listAll(&pb.ListRequest{OrderBy: pb.ListOrder_LIST_ORDER_NAME, PageToken: page.GetNextPageToken()})
+	if !slices.Equal(got, want[2:]) {
+		t.Errorf("ListAll after the first page = %v, want %v", got, want[2:])
+	}
+
+	stream, err := client.ListAll(ctx, &pb.ListRequest{Namespace: "nope"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("ListAll of an unknown namespace = %v, want NotFound", err)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	id := uploadFile(t, client, "a.txt", "one")
+
+	stream, err := client.Upload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Send(&pb.UploadRequest{FileId: id, Data: &pb.UploadRequest_Chunk{Chunk: []byte("two")}})
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("Upload new version: %v", err)
+	}
+	if resp.GetId() != id || resp.GetVersion() != 2 {
+		t.Fatalf("new version = %s@%d, want %s@2", resp.GetId(), resp.GetVersion(), id)
+	}
+
+	tests := []struct {
+		version int64
+		want    string
+		code    codes.Code
+	}{
+		{0, "two", codes.OK},
+		{1, "one", codes.OK},
+		{2, "two", codes.OK},
+		{3, "", codes.NotFound},
+		{-1, "", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		got, code := download(t, client, &pb.DownloadRequest{Id: id, Version: tt.version})
+		if code != tt.code || got != tt.want {
+			t.Errorf("Download(version %d) = %q, %v, want %q, %v", tt.version, got, code, tt.want, tt.code)
+		}
+	}
+
+	info, err := client.RestoreVersion(ctx, &pb.RestoreVersionRequest{Id: id, Version: 1})
+	if err != nil {
+		t.Fatalf("RestoreVersion: %v", err)
+	}
+	if info.GetVersion() != 3 {
+		t.Errorf("restored version = %d, want 3", info.GetVersion())
+	}
+	if got, _ := download(t, client, &pb.DownloadRequest{Id: id}); got != "one" {
+		t.Errorf("content after restore = %q, want %q", got, "one")
+	}
+
+	versions, err := client.ListVersions(ctx, &pb.ListVersionsRequest{Id: id})
+	if err != nil {
+		t.Fatalf("ListVersions: %v", err)
+	}
+	if n := len(versions.GetVersions()); n != 3 {
+		t.Fatalf("ListVersions = %d versions, want 3", n)
+	}
+	if v := versions.GetVersions()[0]; v.GetVersion() != 3 || !v.GetCurrent() {
+		t.Errorf("first version = %d (current %v), want the current version 3", v.GetVersion(), v.GetCurrent())
+	}
+
+	if _, err := client.RestoreVersion(ctx, &pb.RestoreVersionRequest{Id: id, Version: 9}); status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreVersion(9) = %v, want NotFound", err)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	client := newStorageClient(t, storage.Options{TrashRetention: time.Hour}, Config{})
+	ctx := context.Background()
+	id := uploadFile(t, client, "a.txt", "hello")
+
+	if _, err := client.Delete(ctx, &pb.DeleteRequest{Id: id}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, code := download(t, client, &pb.DownloadRequest{Id: id}); code != codes.NotFound {
+		t.Errorf("Download of trashed file = %v, want NotFound", code)
+	}
+
+	trash, err := client.ListTrash(ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if n := len(trash.GetFiles()); n != 1 {
+		t.Fatalf("ListTrash = %d files, want 1", n)
+	}
+	f := trash.GetFiles()[0]
+	if f.GetInfo().GetId() != id || !f.GetPurgeAt().AsTime().Equal(f.GetDeletedAt().AsTime().Add(time.Hour)) {
+		t.Errorf("trashed file = %v, want %s purged an hour after deletion", f, id)
+	}
+
+	if _, err := client.Restore(ctx, &pb.RestoreRequest{Id: id, Namespace: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Restore from unknown namespace = %v, want NotFound", err)
+	}
+	if _, err := client.Restore(ctx, &pb.RestoreRequest{Id: id}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if content, code := download(t, client, &pb.DownloadRequest{Id: id}); code != codes.OK || content != "hello" {
+		t.Errorf("Download after Restore = %q, %v, want %q", content, code, "hello")
+	}
+	if _, err := client.Restore(ctx, &pb.RestoreRequest{Id: id}); status.Code(err) != codes.NotFound {
+		t.Errorf("second Restore = %v, want NotFound", err)
+	}
+}
+
+func TestUploadExpiry(t *testing.T) {
+	client := newTestClient(t)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt *timestamppb.Timestamp
+		ttl       *durationpb.Duration
+		want      codes.Code
+	}{
+		{"none", nil, nil, codes.OK},
+		{"ttl", nil, durationpb.New(time.Hour), codes.OK},
+		{"expires_at", timestamppb.New(now.Add(time.Hour)), nil, codes.OK},
+		{"both", timestamppb.New(now.Add(time.Hour)), durationpb.New(time.Hour), codes.InvalidArgument},
+		{"negative ttl", nil, durationpb.New(-time.Hour), codes.InvalidArgument},
+		{"in the past", timestamppb.New(now.Add(-time.Hour)), nil, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.Upload(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream.Send(&pb.UploadRequest{
+				Data:      &pb.UploadRequest_Filename{Filename: "a.txt"},
+				ExpiresAt: tt.expiresAt,
+				Ttl:       tt.ttl,
+			})
+			stream.Send(&pb.UploadRequest{Data: &pb.UploadRequest_Chunk{Chunk: []byte("hello")}})
+
+			resp, err := stream.CloseAndRecv()
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("Upload = %v, want %v", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			info, err := client.GetInfo(context.Background(), &pb.GetInfoRequest{Id: resp.GetId()})
+			if err != nil {
+				t.Fatalf("GetInfo: %v", err)
+			}
+			if expires := tt.expiresAt != nil || tt.ttl != nil; info.GetExpiresAt() != nil != expires {
+				t.Errorf("expires_at = %v, want set: %v", info.GetExpiresAt(), expires)
+			}
+		})
+	}
+}
+
+func TestCreateFromDigest(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	uploadFile(t, client, "a.txt", "hello")
+
+	tests := []struct {
+		name     string
+		checksum string
+		size     int64
+		want     codes.Code
+	}{
+		{"stored", checksum("hello"), 5, codes.OK},
+		{"upper case", strings.ToUpper(checksum("hello")), 5, codes.OK},
+		{"other size", checksum("hello"), 6, codes.NotFound},
+		{"not stored", checksum("bye"), 3, codes.NotFound},
+		{"not a digest", "hello", 5, codes.InvalidArgument},
+		{"no size", checksum("hello"), 0, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.CreateFromDigest(ctx, &pb.CreateFromDigestRequest{
+				Checksum: tt.checksum,
+				Size:     tt.size,
+				Filename: "copy.txt",
+			})
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("CreateFromDigest = %v, want %v", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			if content, _ := download(t, client, &pb.DownloadRequest{Id: resp.GetId()}); content != "hello" {
+				t.Errorf("copy content = %q, want %q", content, "hello")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CreateNamespace(ctx context.Context, req *pb.CreateNamespaceRequest) (*pb.Namespace, error) {
+	var quota *storage.Quota
+	if q := req.GetQuota(); q != nil {
+		quota = &storage.Quota{MaxBytes: q.GetMaxBytes(), MaxFiles: q.GetMaxFiles()}
+	}
+
+	ns, err := s.storage.CreateNamespace(req.GetName(), quota)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidNamespace), errors.Is(err, storage.ErrInvalidQuota):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+
+		case errors.Is(err, storage.ErrNamespaceExists):
+			return nil, status.Errorf(codes.AlreadyExists, "namespace '%s' already exists", req.GetName())
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to create namespace: %v", err)
+	}
+
+	_, effective, err := s.storage.Usage(ns.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read namespace quota: %v", err)
+	}
+
+	return &pb.Namespace{
+		Name:       ns.Name,
+		CreatedAt:  timestamppb.New(ns.CreatedAt),
+		QuotaBytes: effective.MaxBytes,
+		QuotaFiles: effective.MaxFiles,
+	}, nil
+}
+
+func (s *Server) DeleteNamespace(ctx context.Context, req *pb.DeleteNamespaceRequest) (*pb.DeleteNamespaceResponse, error) {
+	name := req.GetName()
+
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace name cannot be empty")
+	}
+
+	err := s.storage.DeleteNamespace(name)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNamespaceNotFound):
+			return nil, status.Errorf(codes.NotFound, "namespace '%s' not found", name)
+
+		case errors.Is(err, storage.ErrNamespaceNotEmpty):
+			return nil, status.Errorf(codes.FailedPrecondition, "namespace '%s' is not empty", name)
+
+		case errors.Is(err, storage.ErrNamespaceReserved):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to delete namespace: %v", err)
+	}
+
+	return &pb.DeleteNamespaceResponse{}, nil
+}
+
+func (s *Server) ListNamespaces(ctx context.Context, _ *pb.ListNamespacesRequest) (*pb.ListNamespacesResponse, error) {
+	namespaces := s.storage.Namespaces()
+	resp := &pb.ListNamespacesResponse{
+		Namespaces: make([]*pb.Namespace, 0, len(namespaces)),
+	}
+
+	for _, ns := range namespaces {
+		usage, quota, err := s.storage.Usage(ns.Name)
+		if errors.Is(err, storage.ErrNamespaceNotFound) {
+			// Deleted while the list was being built.
+			continue
+		}
+
+		resp.Namespaces = append(resp.Namespaces, &pb.Namespace{
+			Name:       ns.Name,
+			CreatedAt:  timestamppb.New(ns.CreatedAt),
+			UsedBytes:  usage.Bytes,
+			UsedFiles:  usage.Files,
+			QuotaBytes: quota.MaxBytes,
+			QuotaFiles: quota.MaxFiles,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	namespace := namespaceOf(req.GetNamespace())
+
+	files, err := s.storage.Trash(namespace)
+	if err != nil {
+		if errors.Is(err, storage.ErrNamespaceNotFound) {
+			return nil, status.Errorf(codes.NotFound, "namespace '%s' not found", namespace)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to read trash: %v", err)
+	}
+
+	resp := &pb.ListTrashResponse{Files: make([]*pb.TrashedFile, 0, len(files))}
+
+	for _, meta := range files {
+		resp.Files = append(resp.Files, &pb.TrashedFile{
+			Info:      meta.Info(),
+			DeletedAt: timestamppb.New(meta.DeletedAt),
+			PurgeAt:   timestamppb.New(s.storage.PurgeAt(meta)),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *Server) Restore(ctx context.Context, req *pb.RestoreRequest) (*pb.FileInfo, error) {
+	fileID := req.GetId()
+
+	if fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "id cannot be empty")
+	}
+
+	namespace := namespaceOf(req.GetNamespace())
+
+	meta, err := s.storage.Restore(namespace, fileID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "deleted file with id '%s' not found", fileID)
+		}
+		if err := admissionError(namespace, fileID, err); err != nil {
+			return nil, err
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to restore file: %v", err)
+	}
+
+	return meta.Info(), nil
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/YotoHana/tages-test-case/api/proto"
+	"github.com/YotoHana/tages-test-case/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	fileID := req.GetId()
+
+	if fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "id cannot be empty")
+	}
+
+	versions, err := s.storage.Versions(namespaceOf(req.GetNamespace()), fileID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to list versions: %v", err)
+	}
+
+	resp := &pb.ListVersionsResponse{Versions: make([]*pb.FileVersion, 0, len(versions))}
+
+	for i, v := range versions {
+		resp.Versions = append(resp.Versions, &pb.FileVersion{
+			Version:     v.Version,
+			Size:        v.Size,
+			ContentType: v.ContentType,
+			Checksum:    v.Checksum,
+			CreatedAt:   timestamppb.New(v.CreatedAt),
+			Current:     i == 0,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *Server) RestoreVersion(ctx context.Context, req *pb.RestoreVersionRequest) (*pb.FileInfo, error) {
+	fileID := req.GetId()
+
+	if fileID == "" {
+		return nil, status.Error(codes.InvalidArgument, "id cannot be empty")
+	}
+	if req.GetVersion() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "version must be positive")
+	}
+
+	meta, err := s.storage.RestoreVersion(namespaceOf(req.GetNamespace()), fileID, req.GetVersion())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "file with id '%s' not found", fileID)
+		}
+		if errors.Is(err, storage.ErrVersionNotFound) {
+			return nil, status.Errorf(codes.NotFound, "version %d of file '%s' not found", req.GetVersion(), fileID)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to restore version: %v", err)
+	}
+
+	return meta.Info(), nil
+}
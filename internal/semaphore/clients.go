@@ -0,0 +1,174 @@
+package semaphore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+)
+
+const (
+	TooManyClientReqs = "too many concurent requests from this client"
+	RateExceeded      = "request rate limit exceeded"
+)
+
+// ClientLimit is what a single client may do, over all methods. Zero fields
+// mean no limit.
+type ClientLimit struct {
+	Concurrency int
+	Rate        rate.Limit // calls per second
+	Burst       int
+}
+
+// Clients limits every caller on its own, so one misbehaving client cannot
+// use up the slots everyone shares. Callers are told apart by identities.
+type Clients struct {
+	limit      ClientLimit
+	idle       time.Duration
+	identities *Identities
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+type client struct {
+	sem      *Semaphore
+	rate     *rate.Limiter
+	times    callTime
+	active   int
+	lastSeen time.Time
+}
+
+// NewClients keeps the state of a client until it has been idle for idle.
+func NewClients(limit ClientLimit, idle time.Duration, identities *Identities) *Clients {
+	if limit.Rate > 0 && limit.Burst <= 0 {
+		limit.Burst = max(1, int(limit.Rate))
+	}
+
+	return &Clients{
+		limit:      limit,
+		idle:       idle,
+		identities: identities,
+		clients:    make(map[string]*client),
+	}
+}
+
+// Evict forgets clients that have had no call running since idle before now,
+// and returns how many were removed.
+func (c *Clients) Evict(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key, cl := range c.clients {
+		if cl.active == 0 && now.Sub(cl.lastSeen) > c.idle {
+			delete(c.clients, key)
+			n++
+		}
+	}
+
+	return n
+}
+
+// Len is how many clients are tracked.
+func (c *Clients) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.clients)
+}
+
+// admit checks a call against the limits of its client, returning the func
+// that ends the call, or the status it is rejected with.
+func (c *Clients) admit(ctx context.Context) (func(), error) {
+	key := c.identities.Of(ctx)
+
+	c.mu.Lock()
+	cl, ok := c.clients[key]
+	if !ok {
+		cl = &client{}
+		if c.limit.Concurrency > 0 {
+			cl.sem = NewSemaphore(c.limit.Concurrency)
+		}
+		if c.limit.Rate > 0 {
+			cl.rate = rate.NewLimiter(c.limit.Rate, c.limit.Burst)
+		}
+		c.clients[key] = cl
+	}
+	cl.active++
+	cl.lastSeen = time.Now()
+	c.mu.Unlock()
+
+	done := func() {
+		c.mu.Lock()
+		cl.active--
+		cl.lastSeen = time.Now()
+		c.mu.Unlock()
+	}
+
+	if cl.rate != nil && !cl.rate.Allow() {
+		done()
+
+		// Reserving tells when the next call is allowed; it is handed back
+		// right away, as this call is rejected anyway.
+		r := cl.rate.Reserve()
+		delay := r.Delay()
+		r.Cancel()
+
+		description := fmt.Sprintf("at most %g calls per second per client", float64(c.limit.Rate))
+		return nil, exhausted(RateExceeded, delay, key, description)
+	}
+
+	if cl.sem == nil {
+		return done, nil
+	}
+
+	if !cl.sem.TryAcquire() {
+		done()
+
+		description := fmt.Sprintf("at most %d concurrent calls per client", c.limit.Concurrency)
+		return nil, exhausted(TooManyClientReqs, cl.times.slotIn(c.limit.Concurrency, 0), key, description)
+	}
+
+	finish := release(cl.sem, &cl.times)
+
+	return func() {
+		finish()
+		done()
+	}, nil
+}
+
+func ClientLimitStream(clients *Clients) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		done, err := clients.admit(ss.Context())
+		if err != nil {
+			return err
+		}
+		defer done()
+
+		return handler(srv, ss)
+	}
+}
+
+func ClientLimitUnary(clients *Clients) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp any, err error) {
+		done, err := clients.admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,101 @@
+package semaphore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientLimitConcurrency(t *testing.T) {
+	clients := NewClients(ClientLimit{Concurrency: 1}, time.Minute, nil)
+	a := callerContext("10.0.0.1", "")
+	b := callerContext("10.0.0.2", "")
+
+	done, err := clients.admit(a)
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+
+	if _, err := clients.admit(a); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second call of a busy client: got %v, want ResourceExhausted", err)
+	}
+
+	other, err := clients.admit(b)
+	if err != nil {
+		t.Errorf("a busy client took the slot of another: %v", err)
+	} else {
+		other()
+	}
+
+	done()
+	if done, err := clients.admit(a); err != nil {
+		t.Errorf("admit after release: %v", err)
+	} else {
+		done()
+	}
+}
+
+func TestClientLimitRate(t *testing.T) {
+	clients := NewClients(ClientLimit{Rate: 1, Burst: 2}, time.Minute, nil)
+	interceptor := ClientLimitUnary(clients)
+	ctx := callerContext("10.0.0.1", "")
+
+	for i := range 2 {
+		if err := unary(ctx, interceptor, listMethod); err != nil {
+			t.Fatalf("call %d within burst: %v", i, err)
+		}
+	}
+
+	err := unary(ctx, interceptor, listMethod)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("call over burst: got %v, want ResourceExhausted", err)
+	}
+	if msg := status.Convert(err).Message(); msg != RateExceeded {
+		t.Errorf("message = %q, want %q", msg, RateExceeded)
+	}
+
+	if err := unary(callerContext("10.0.0.2", ""), interceptor, listMethod); err != nil {
+		t.Errorf("another client was limited: %v", err)
+	}
+}
+
+func TestClientsEvict(t *testing.T) {
+	clients := NewClients(ClientLimit{Concurrency: 1}, time.Minute, nil)
+
+	busy, err := clients.admit(callerContext("10.0.0.1", ""))
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	idle, err := clients.admit(callerContext("10.0.0.2", ""))
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	idle()
+
+	if n := clients.Evict(time.Now()); n != 0 {
+		t.Errorf("Evict before idle timeout removed %d clients", n)
+	}
+
+	if n := clients.Evict(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Errorf("Evict removed %d clients, want only the idle one", n)
+	}
+	if n := clients.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+
+	busy()
+}
+
+func TestClientLimitNone(t *testing.T) {
+	clients := NewClients(ClientLimit{}, time.Minute, nil)
+	ctx := context.Background()
+
+	for i := range 100 {
+		if err := unary(ctx, ClientLimitUnary(clients), listMethod); err != nil {
+			t.Fatalf("call %d with no limits: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package semaphore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// APIKeyHeader is the metadata key a client identifies itself with.
+const APIKeyHeader = "x-api-key"
+
+// Identities tells callers apart for per-client limits. Only API keys it
+// was given are trusted: a key anyone could make up would let a client get
+// fresh limits with every call.
+type Identities struct {
+	apiKeys map[string]bool
+}
+
+func NewIdentities(apiKeys []string) *Identities {
+	id := &Identities{apiKeys: make(map[string]bool, len(apiKeys))}
+	for _, key := range apiKeys {
+		id.apiKeys[key] = true
+	}
+
+	return id
+}
+
+// Of is the key a caller is limited by: the subject of its verified client
+// certificate over mTLS, else its API key from the x-api-key metadata if the
+// key is known, else its IP address. A nil Identities knows no API keys.
+// The key stands for an API key by a hash, so it can be shown to anyone.
+func (id *Identities) Of(ctx context.Context) string {
+	p, _ := peer.FromContext(ctx)
+
+	if p != nil {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			return "cert:" + info.State.VerifiedChains[0][0].Subject.String()
+		}
+	}
+
+	if id != nil {
+		if keys := metadata.ValueFromIncomingContext(ctx, APIKeyHeader); len(keys) > 0 && id.apiKeys[keys[0]] {
+			return "key:" + keyHash(keys[0])
+		}
+	}
+
+	if p == nil || p.Addr == nil {
+		return "addr:unknown"
+	}
+
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	return "addr:" + addr
+}
+
+// keyHash is a short SHA-256 of an API key: enough to tell keys apart, but
+// not to give one away.
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,90 @@
+package semaphore
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// callerContext is the context of a call from addr with the given API key,
+// if any.
+func callerContext(addr, key string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 50123},
+	})
+	if key != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIKeyHeader, key))
+	}
+
+	return ctx
+}
+
+func TestIdentitiesOf(t *testing.T) {
+	identities := NewIdentities([]string{"secret-key"})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"address", callerContext("10.0.0.1", ""), "addr:10.0.0.1"},
+		{"unknown key", callerContext("10.0.0.1", "made-up"), "addr:10.0.0.1"},
+		{"known key", callerContext("10.0.0.1", "secret-key"), "key:" + keyHash("secret-key")},
+		{"no peer", context.Background(), "addr:unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identities.Of(tt.ctx); got != tt.want {
+				t.Errorf("Of() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// The identity ends up in errors sent to clients, so it must not carry the
+// key itself.
+func TestIdentitiesOfHidesKey(t *testing.T) {
+	identities := NewIdentities([]string{"secret-key"})
+
+	got := identities.Of(callerContext("10.0.0.1", "secret-key"))
+	if strings.Contains(got, "secret-key") {
+		t.Errorf("Of() = %q, has the API key in it", got)
+	}
+	if other := identities.Of(callerContext("10.0.0.2", "secret-key")); other != got {
+		t.Errorf("one key gave %q and %q from different addresses", got, other)
+	}
+}
+
+func TestNilIdentities(t *testing.T) {
+	var identities *Identities
+
+	if got := identities.Of(callerContext("10.0.0.1", "secret-key")); got != "addr:10.0.0.1" {
+		t.Errorf("Of() = %q, want addr:10.0.0.1", got)
+	}
+}
+
+func TestIdentitiesOfCert(t *testing.T) {
+	identities := NewIdentities([]string{"secret-key"})
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "backup-job"}}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50123},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIKeyHeader, "secret-key"))
+
+	if got := identities.Of(ctx); got != "cert:CN=backup-job" {
+		t.Errorf("Of() = %q, want cert:CN=backup-job", got)
+	}
+}
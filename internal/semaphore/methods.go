@@ -0,0 +1,171 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	WaitTimedOut = "timed out waiting for a free slot"
+)
+
+// Limit is how a method is admitted. Concurrency calls may run at once, 0
+// means no limit. With Queue 0 a call over the limit is rejected right away;
+// otherwise up to Queue calls wait for a slot, each for at most MaxWait (0
+// leaves only the call's own deadline) before being rejected.
+type Limit struct {
+	Concurrency int
+	Queue       int
+	MaxWait     time.Duration
+}
+
+// Methods gives every gRPC method a semaphore of its own, so a burst of one
+// kind of request cannot use up the slots of another. Methods are keyed by
+// full name, e.g. "/fileservice.FileService/Upload"; those missing from the
+// table get their own semaphore with the default limit on first use.
+type Methods struct {
+	limits       map[string]Limit
+	defaultLimit Limit
+
+	mu         sync.Mutex
+	semaphores map[string]*Semaphore
+	callTimes  map[string]*callTime
+}
+
+func NewMethods(limits map[string]Limit, defaultLimit Limit) *Methods {
+	return &Methods{
+		limits:       limits,
+		defaultLimit: defaultLimit,
+		semaphores:   make(map[string]*Semaphore),
+		callTimes:    make(map[string]*callTime),
+	}
+}
+
+// For returns the semaphore of a method, or nil if it is not limited.
+func (m *Methods) For(method string) *Semaphore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sem, ok := m.semaphores[method]
+	if !ok {
+		if limit := m.Limit(method); limit.Concurrency > 0 {
+			sem = NewQueuedSemaphore(limit.Concurrency, limit.Queue)
+		}
+		m.semaphores[method] = sem
+		m.callTimes[method] = &callTime{}
+	}
+
+	return sem
+}
+
+func (m *Methods) Limit(method string) Limit {
+	if limit, ok := m.limits[method]; ok {
+		return limit
+	}
+
+	return m.defaultLimit
+}
+
+// admit takes a slot of the method for a call, returning the func that
+// releases it, or the status the call is rejected with.
+func (m *Methods) admit(ctx context.Context, method string) (func(), error) {
+	limiter := m.For(method)
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	limit := m.Limit(method)
+
+	m.mu.Lock()
+	times := m.callTimes[method]
+	m.mu.Unlock()
+
+	if limit.Queue == 0 {
+		if !limiter.TryAcquire() {
+			return nil, methodExhausted(method, limit, limiter, times, TooManyReqs)
+		}
+		return release(limiter, times), nil
+	}
+
+	wait := ctx
+	if limit.MaxWait > 0 {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(ctx, limit.MaxWait)
+		defer cancel()
+	}
+
+	err := limiter.Acquire(wait)
+	switch {
+	case err == nil:
+		return release(limiter, times), nil
+
+	case errors.Is(err, ErrQueueFull):
+		return nil, methodExhausted(method, limit, limiter, times, TooManyReqs)
+
+	case ctx.Err() != nil:
+		// The caller gave up or ran out of its own deadline.
+		return nil, status.FromContextError(ctx.Err()).Err()
+
+	default:
+		return nil, methodExhausted(method, limit, limiter, times, WaitTimedOut)
+	}
+}
+
+func methodExhausted(method string, limit Limit, limiter *Semaphore, times *callTime, msg string) error {
+	description := fmt.Sprintf("at most %d concurrent calls", limit.Concurrency)
+	if limit.Queue > 0 {
+		description += fmt.Sprintf(" and %d queued", limit.Queue)
+	}
+
+	delay := times.slotIn(limit.Concurrency, limiter.Waiting())
+
+	return exhausted(msg, delay, method, description)
+}
+
+// release frees the slot of a call and records how long it was held.
+func release(limiter *Semaphore, times *callTime) func() {
+	start := time.Now()
+
+	return func() {
+		limiter.Release()
+		times.observe(time.Since(start))
+	}
+}
+
+func MethodLimitStream(methods *Methods) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		release, err := methods.admit(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return handler(srv, ss)
+	}
+}
+
+func MethodLimitUnary(methods *Methods) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp any, err error) {
+		release, err := methods.admit(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,129 @@
+package semaphore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	uploadMethod   = "/fileservice.FileService/Upload"
+	downloadMethod = "/fileservice.FileService/Download"
+	listMethod     = "/fileservice.FileService/List"
+)
+
+func TestMethodsLimit(t *testing.T) {
+	methods := NewMethods(map[string]Limit{
+		uploadMethod:   {Concurrency: 1, Queue: 2},
+		downloadMethod: {},
+	}, Limit{Concurrency: 5})
+
+	tests := []struct {
+		method string
+		want   Limit
+	}{
+		{uploadMethod, Limit{Concurrency: 1, Queue: 2}},
+		{downloadMethod, Limit{}},
+		{listMethod, Limit{Concurrency: 5}},
+	}
+
+	for _, tt := range tests {
+		if got := methods.Limit(tt.method); got != tt.want {
+			t.Errorf("Limit(%s) = %+v, want %+v", tt.method, got, tt.want)
+		}
+	}
+
+	if methods.For(downloadMethod) != nil {
+		t.Error("unlimited method got a semaphore")
+	}
+	if methods.For(listMethod) != methods.For(listMethod) {
+		t.Error("method got a new semaphore on every call")
+	}
+}
+
+// unary calls a handler that does nothing through the interceptor.
+func unary(ctx context.Context, interceptor grpc.UnaryServerInterceptor, method string) error {
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, any) (any, error) { return nil, nil })
+	return err
+}
+
+// A method that has used up its slots must not take those of another.
+func TestMethodLimitUnary(t *testing.T) {
+	methods := NewMethods(map[string]Limit{uploadMethod: {Concurrency: 1}}, Limit{Concurrency: 1})
+	interceptor := MethodLimitUnary(methods)
+	ctx := context.Background()
+
+	methods.For(uploadMethod).TryAcquire()
+
+	if code := status.Code(unary(ctx, interceptor, uploadMethod)); code != codes.ResourceExhausted {
+		t.Errorf("call over the limit = %v, want ResourceExhausted", code)
+	}
+	if err := unary(ctx, interceptor, listMethod); err != nil {
+		t.Errorf("call of another method = %v, want it to run", err)
+	}
+
+	methods.For(uploadMethod).Release()
+
+	if err := unary(ctx, interceptor, uploadMethod); err != nil {
+		t.Errorf("call after a slot was freed = %v, want it to run", err)
+	}
+	if err := unary(ctx, interceptor, uploadMethod); err != nil {
+		t.Errorf("slot not released after the call: %v", err)
+	}
+}
+
+func TestMethodLimitQueue(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		// deadline is the call's own, 0 for none.
+		deadline time.Duration
+		// release frees the held slot while the call waits.
+		release bool
+		want    codes.Code
+		message string
+	}{
+		{"admitted once a slot is free", Limit{Concurrency: 1, Queue: 1, MaxWait: time.Second}, 0, true, codes.OK, ""},
+		{"max wait", Limit{Concurrency: 1, Queue: 1, MaxWait: 10 * time.Millisecond}, 0, false, codes.ResourceExhausted, WaitTimedOut},
+		{"call deadline", Limit{Concurrency: 1, Queue: 1}, 10 * time.Millisecond, false, codes.DeadlineExceeded, ""},
+		{"no queue", Limit{Concurrency: 1}, 0, false, codes.ResourceExhausted, TooManyReqs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods := NewMethods(nil, tt.limit)
+			interceptor := MethodLimitUnary(methods)
+			sem := methods.For(uploadMethod)
+			sem.TryAcquire()
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			if tt.release {
+				go func() {
+					for sem.Waiting() == 0 {
+						time.Sleep(time.Millisecond)
+					}
+					sem.Release()
+				}()
+			}
+
+			err := unary(ctx, interceptor, uploadMethod)
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("call = %v, want %v", err, tt.want)
+			}
+			if tt.message != "" && status.Convert(err).Message() != tt.message {
+				t.Errorf("message = %q, want %q", status.Convert(err).Message(), tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package semaphore
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+
+	// defaultCallTime is assumed until a call of a method has finished.
+	defaultCallTime = time.Second
+)
+
+// exhausted is the status a call over a limit is rejected with. Its details
+// name the limit (QuotaFailure) and suggest when to try again (RetryInfo).
+func exhausted(msg string, delay time.Duration, subject, description string) error {
+	st := status.New(codes.ResourceExhausted, msg)
+
+	delay = min(max(delay, minRetryDelay), maxRetryDelay)
+
+	detailed, err := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+		&errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{
+				{Subject: subject, Description: description},
+			},
+		},
+	)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// callTime is a moving average of how long calls hold their slot, used to
+// estimate when the next one frees up.
+type callTime struct {
+	mu  sync.Mutex
+	avg time.Duration
+}
+
+func (c *callTime) observe(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.avg == 0 {
+		c.avg = d
+		return
+	}
+
+	c.avg += (d - c.avg) / 8
+}
+
+// slotIn estimates how long a caller waits for one of limit slots with ahead
+// callers in front of it: on average a slot frees up every avg/limit.
+func (c *callTime) slotIn(limit, ahead int) time.Duration {
+	c.mu.Lock()
+	avg := c.avg
+	c.mu.Unlock()
+
+	if avg == 0 {
+		avg = defaultCallTime
+	}
+
+	return avg * time.Duration(ahead+1) / time.Duration(limit)
+}
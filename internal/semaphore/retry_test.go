@@ -0,0 +1,99 @@
+package semaphore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// details returns the RetryInfo and QuotaFailure of a rejection, failing the
+// test if it is not one.
+func details(t *testing.T, err error) (*errdetails.RetryInfo, *errdetails.QuotaFailure) {
+	t.Helper()
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted", err)
+	}
+
+	var info *errdetails.RetryInfo
+	var quota *errdetails.QuotaFailure
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.RetryInfo:
+			info = d
+		case *errdetails.QuotaFailure:
+			quota = d
+		}
+	}
+	if info == nil || quota == nil || len(quota.Violations) != 1 {
+		t.Fatalf("details = %v, want a RetryInfo and a QuotaFailure with one violation", st.Details())
+	}
+
+	return info, quota
+}
+
+func TestExhaustedDelay(t *testing.T) {
+	tests := []struct {
+		delay, want time.Duration
+	}{
+		{0, minRetryDelay},
+		{time.Second, time.Second},
+		{time.Hour, maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		info, _ := details(t, exhausted(TooManyReqs, tt.delay, uploadMethod, "limit"))
+		if got := info.RetryDelay.AsDuration(); got != tt.want {
+			t.Errorf("retry delay for %v = %v, want %v", tt.delay, got, tt.want)
+		}
+	}
+}
+
+func TestMethodLimitDetails(t *testing.T) {
+	methods := NewMethods(map[string]Limit{uploadMethod: {Concurrency: 1}}, Limit{})
+	done, err := methods.admit(callerContext("10.0.0.1", ""), uploadMethod)
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	defer done()
+
+	_, err = methods.admit(callerContext("10.0.0.1", ""), uploadMethod)
+	_, quota := details(t, err)
+
+	v := quota.Violations[0]
+	if v.Subject != uploadMethod || v.Description != "at most 1 concurrent calls" {
+		t.Errorf("violation = %q %q, want the method and its limit", v.Subject, v.Description)
+	}
+}
+
+func TestClientLimitDetails(t *testing.T) {
+	clients := NewClients(ClientLimit{Rate: 1, Burst: 1}, time.Minute, NewIdentities([]string{"secret-key"}))
+	ctx := callerContext("10.0.0.1", "secret-key")
+
+	done, err := clients.admit(ctx)
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	done()
+
+	_, err = clients.admit(ctx)
+	info, quota := details(t, err)
+
+	// The next token comes in a second.
+	if delay := info.RetryDelay.AsDuration(); delay < 900*time.Millisecond || delay > time.Second {
+		t.Errorf("retry delay = %v, want about 1s", delay)
+	}
+
+	subject := quota.Violations[0].Subject
+	if subject != "key:"+keyHash("secret-key") {
+		t.Errorf("subject = %q, want the hashed key", subject)
+	}
+	if strings.Contains(subject, "secret-key") || strings.Contains(err.Error(), "secret-key") {
+		t.Error("the rejection gives the API key away")
+	}
+}
@@ -1,72 +1,113 @@
 package semaphore
 
 import (
+	"container/list"
 	"context"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"errors"
+	"sync"
 )
 
 const (
 	TooManyReqs = "too many concurent requests"
 )
 
+var ErrQueueFull = errors.New("semaphore queue is full")
+
+// Semaphore allows up to limit holders at a time. Acquire waits for a slot in
+// FIFO order; TryAcquire never waits and never overtakes a waiting caller.
 type Semaphore struct {
-	slots chan struct{}
+	mu      sync.Mutex
+	limit   int
+	queue   int
+	held    int
+	waiters list.List // of chan struct{}, closed once the slot is handed over
 }
 
+// Acquire waits for a slot until ctx is done. If queue callers are already
+// waiting, it fails right away with ErrQueueFull.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	s.mu.Lock()
+	if s.held < s.limit && s.waiters.Len() == 0 {
+		s.held++
+		s.mu.Unlock()
+		return nil
+	}
 
-func (s *Semaphore) Acquire() {
-	s.slots <- struct{}{}
+	if s.queue > 0 && s.waiters.Len() >= s.queue {
+		s.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// The slot was handed over just as ctx was done: pass it on.
+			s.mu.Unlock()
+			s.Release()
+
+		default:
+			s.waiters.Remove(elem)
+			s.mu.Unlock()
+		}
+
+		return ctx.Err()
+	}
 }
 
 func (s *Semaphore) TryAcquire() bool {
-	select {
-	case s.slots <- struct{}{}:
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.held < s.limit && s.waiters.Len() == 0 {
+		s.held++
 		return true
-		
-	default:
-		return false
 	}
+
+	return false
 }
 
+// Release frees a slot, handing it straight to the longest waiting caller
+// if there is one.
 func (s *Semaphore) Release() {
-	<- s.slots
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if front := s.waiters.Front(); front != nil {
+		close(s.waiters.Remove(front).(chan struct{}))
+		return
+	}
+
+	s.held--
+}
+
+// Waiting is how many callers are queued in Acquire.
+func (s *Semaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.waiters.Len()
 }
 
 func NewSemaphore(limit int) *Semaphore {
 	return &Semaphore{
-		slots: make(chan struct{}, limit),
+		limit: limit,
 	}
 }
 
-func RateLimitStream(limiter *Semaphore) grpc.StreamServerInterceptor {
-	return func(
-		srv any,
-		ss grpc.ServerStream,
-		info *grpc.StreamServerInfo,
-		handler grpc.StreamHandler) error {
-			if !limiter.TryAcquire() {
-				return status.Error(codes.ResourceExhausted, TooManyReqs)
-			}
-			defer limiter.Release()
-
-			return handler(srv, ss)
-		}
+// NewQueuedSemaphore is NewSemaphore with at most queue callers waiting in
+// Acquire; 0 means no bound.
+func NewQueuedSemaphore(limit, queue int) *Semaphore {
+	return &Semaphore{
+		limit: limit,
+		queue: queue,
+	}
 }
-
-func RateLimitUnary(limiter *Semaphore) grpc.UnaryServerInterceptor {
-	return func(
-		ctx context.Context,
-		req any,
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler) (resp any, err error) {
-			if !limiter.TryAcquire() {
-				return nil, status.Error(codes.ResourceExhausted, TooManyReqs)
-			}
-			defer limiter.Release()
-
-			return handler(ctx, req)
-		}
-}
\ No newline at end of file
@@ -0,0 +1,154 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls until the semaphore has n callers queued in Acquire.
+func waitFor(t *testing.T, sem *Semaphore, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for sem.Waiting() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("waiting = %d, want %d", sem.Waiting(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	const waiters = 5
+
+	sem := NewSemaphore(1)
+	if !sem.TryAcquire() {
+		t.Fatal("TryAcquire on a free semaphore failed")
+	}
+
+	order := make(chan int, waiters)
+	for i := range waiters {
+		go func() {
+			if err := sem.Acquire(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+		}()
+		// Queued one at a time, so the queue order is known.
+		waitFor(t, sem, i+1)
+	}
+
+	for want := range waiters {
+		sem.Release()
+
+		if got := <-order; got != want {
+			t.Fatalf("slot handed to waiter %d, want %d", got, want)
+		}
+
+		// The slot went straight to the waiter: it cannot be taken over
+		// in between.
+		if sem.TryAcquire() {
+			t.Fatal("TryAcquire took a slot that was handed over")
+		}
+	}
+
+	sem.Release()
+	if !sem.TryAcquire() {
+		t.Fatal("slot not freed after the last release")
+	}
+}
+
+func TestSemaphoreAcquire(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		queue int
+		// held slots and queued callers when Acquire is called.
+		held   int
+		queued int
+		want   error
+	}{
+		{"free slot", 2, 0, 1, 0, nil},
+		{"times out", 1, 0, 1, 0, context.DeadlineExceeded},
+		{"queue has room", 1, 2, 1, 1, context.DeadlineExceeded},
+		{"queue full", 1, 1, 1, 1, ErrQueueFull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sem := NewQueuedSemaphore(tt.limit, tt.queue)
+			for range tt.held {
+				if !sem.TryAcquire() {
+					t.Fatal("TryAcquire failed")
+				}
+			}
+
+			queued, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var wg sync.WaitGroup
+			for i := range tt.queued {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sem.Acquire(queued)
+				}()
+				waitFor(t, sem, i+1)
+			}
+
+			ctx, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer stop()
+
+			if err := sem.Acquire(ctx); !errors.Is(err, tt.want) {
+				t.Errorf("Acquire = %v, want %v", err, tt.want)
+			}
+
+			// A caller that gave up leaves the queue.
+			cancel()
+			wg.Wait()
+			if n := sem.Waiting(); n != 0 {
+				t.Errorf("waiting after every caller gave up = %d", n)
+			}
+		})
+	}
+}
+
+// A slot handed over just as the waiter's context is done must be passed on
+// rather than lost.
+func TestSemaphoreAcquireCancelRace(t *testing.T) {
+	sem := NewSemaphore(1)
+
+	for range 1000 {
+		if !sem.TryAcquire() {
+			t.Fatal("slot lost")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		acquired := make(chan error)
+		go func() {
+			acquired <- sem.Acquire(ctx)
+		}()
+		waitFor(t, sem, 1)
+
+		// The waiter is woken by ctx, but usually still queued when the
+		// slot is handed to it right after.
+		cancel()
+		sem.Release()
+
+		if err := <-acquired; err == nil {
+			sem.Release()
+		}
+	}
+
+	if !sem.TryAcquire() {
+		t.Fatal("slot lost")
+	}
+	if n := sem.Waiting(); n != 0 {
+		t.Errorf("waiting = %d, want 0", n)
+	}
+}
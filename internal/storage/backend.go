@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"io"
+	"time"
+)
+
+// Backend stores opaque blobs under slash-separated keys. Storage keeps the
+// file index on top of it, so a driver only has to move bytes around.
+type Backend interface {
+	Create(key string) (BlobWriter, error)
+	Open(key string) (io.ReadSeekCloser, error)
+	Stat(key string) (BlobInfo, error)
+	List(prefix string) ([]BlobInfo, error)
+	Delete(key string) error
+	// Rename moves a blob to another key, replacing whatever is stored
+	// there.
+	Rename(from, to string) error
+}
+
+// BlobWriter is a blob being written. Nothing is visible under the key
+// until Close succeeds; Abort throws the data away instead.
+type BlobWriter interface {
+	io.WriteCloser
+	Abort() error
+}
+
+type BlobInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
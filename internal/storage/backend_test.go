@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// backends are the drivers every test in this file runs against.
+func backends(t *testing.T) map[string]Backend {
+	t.Helper()
+
+	fs, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]Backend{"fs": fs, "memory": NewMemory()}
+}
+
+func put(t *testing.T, backend Backend, key, content string) {
+	t.Helper()
+
+	w, err := backend.Create(key)
+	if err != nil {
+		t.Fatalf("Create(%s): %v", key, err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Write(%s): %v", key, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(%s): %v", key, err)
+	}
+}
+
+func get(t *testing.T, backend Backend, key string) string {
+	t.Helper()
+
+	r, err := backend.Open(key)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", key, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+
+	return string(data)
+}
+
+func listKeys(t *testing.T, backend Backend, prefix string) []string {
+	t.Helper()
+
+	infos, err := backend.List(prefix)
+	if err != nil {
+		t.Fatalf("List(%q): %v", prefix, err)
+	}
+
+	var keys []string
+	for _, info := range infos {
+		keys = append(keys, info.Key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+func TestBackend(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			put(t, backend, "blobs/a", "hello")
+			put(t, backend, "blobs/b.tmp", "temporary name, real content")
+			put(t, backend, "index.json", "{}")
+
+			if got := get(t, backend, "blobs/a"); got != "hello" {
+				t.Errorf("content = %q, want %q", got, "hello")
+			}
+
+			info, err := backend.Stat("blobs/a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Key != "blobs/a" || info.Size != 5 || info.ModTime.IsZero() {
+				t.Errorf("Stat = %+v", info)
+			}
+
+			tests := []struct {
+				prefix string
+				want   []string
+			}{
+				{"", []string{"blobs/a", "blobs/b.tmp", "index.json"}},
+				{"blobs/", []string{"blobs/a", "blobs/b.tmp"}},
+				{"nothing/", nil},
+			}
+			for _, tt := range tests {
+				if got := listKeys(t, backend, tt.prefix); !slices.Equal(got, tt.want) {
+					t.Errorf("List(%q) = %v, want %v", tt.prefix, got, tt.want)
+				}
+			}
+
+			put(t, backend, "blobs/a", "replaced")
+			if got := get(t, backend, "blobs/a"); got != "replaced" {
+				t.Errorf("content after overwrite = %q, want %q", got, "replaced")
+			}
+
+			if err := backend.Delete("blobs/a"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := backend.Open("blobs/a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Open after Delete = %v, want ErrNotFound", err)
+			}
+			if _, err := backend.Stat("blobs/a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Stat after Delete = %v, want ErrNotFound", err)
+			}
+			if err := backend.Delete("blobs/a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("second Delete = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+// A blob being written is not visible under any key until it is closed.
+func TestBackendUnfinishedWrite(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			w, err := backend.Create("blobs/a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte("half")); err != nil {
+				t.Fatal(err)
+			}
+
+			if keys := listKeys(t, backend, ""); len(keys) != 0 {
+				t.Errorf("keys while writing = %v, want none", keys)
+			}
+			if _, err := backend.Open("blobs/a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Open while writing = %v, want ErrNotFound", err)
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if keys := listKeys(t, backend, ""); !slices.Equal(keys, []string{"blobs/a"}) {
+				t.Errorf("keys after Close = %v", keys)
+			}
+		})
+	}
+}
+
+func TestFSBackendInvalidKey(t *testing.T) {
+	backend, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../outside", "/etc/passwd", "blobs/../../outside"} {
+		if _, err := backend.Create(key); err == nil {
+			t.Errorf("Create(%q) succeeded", key)
+		}
+		if _, err := backend.Open(key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) = %v, want an invalid key error", key, err)
+		}
+	}
+}
+
+func TestBackendAbort(t *testing.T) {
+	for name, backend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			put(t, backend, "blobs/a", "old")
+
+			w, err := backend.Create("blobs/a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte("new")); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Abort(); err != nil {
+				t.Fatalf("Abort: %v", err)
+			}
+
+			if got := get(t, backend, "blobs/a"); got != "old" {
+				t.Errorf("content after Abort = %q, want %q", got, "old")
+			}
+			if keys := listKeys(t, backend, ""); !slices.Equal(keys, []string{"blobs/a"}) {
+				t.Errorf("keys after Abort = %v", keys)
+			}
+		})
+	}
+}
+
+// Opening the fs backend clears what a crashed process left in the staging
+// directory and nothing else, whatever the names.
+func TestFSBackendSweep(t *testing.T) {
+	root := t.TempDir()
+
+	backend, err := NewFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(t, backend, "blobs/a.tmp", "a file named like a temporary one")
+
+	w, err := backend.Create("blobs/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("interrupted")); err != nil {
+		t.Fatal(err)
+	}
+	staged, err := os.ReadDir(filepath.Join(root, stagingDir))
+	if err != nil || len(staged) != 1 {
+		t.Fatalf("staged files = %v, %v, want one", staged, err)
+	}
+
+	backend, err = NewFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staged, err = os.ReadDir(filepath.Join(root, stagingDir))
+	if err != nil || len(staged) != 0 {
+		t.Errorf("staged files after restart = %v, %v, want none", staged, err)
+	}
+	if got := get(t, backend, "blobs/a.tmp"); got != "a file named like a temporary one" {
+		t.Errorf("content = %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// blobRef counts what keeps a content blob alive: index entries referring
+// to it, trashed files included, and commits of the same content that are
+// still in flight.
+type blobRef struct {
+	refs int
+	pins int
+	size int64
+}
+
+// DedupStats compares the size of everything indexed with what the backend
+// actually stores once identical content is shared.
+type DedupStats struct {
+	Blobs        int   `json:"blobs"`
+	LogicalBytes int64 `json:"logical_bytes"`
+	StoredBytes  int64 `json:"stored_bytes"`
+	SavedBytes   int64 `json:"saved_bytes"`
+}
+
+func (s *Storage) DedupStats() DedupStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats DedupStats
+
+	for _, index := range []map[string]*FileMeta{s.files, s.trash} {
+		for _, meta := range index {
+			stats.LogicalBytes += meta.storedSize()
+		}
+	}
+
+	for _, ref := range s.refs {
+		if ref.refs > 0 {
+			stats.Blobs++
+			stats.StoredBytes += ref.size
+		}
+	}
+
+	stats.SavedBytes = stats.LogicalBytes - stats.StoredBytes
+
+	return stats
+}
+
+// link counts meta's blobs as referenced by one more index entry, and its
+// content as findable. Callers must hold s.mu.
+func (s *Storage) link(meta *FileMeta) {
+	meta.eachBlob(func(name string, size int64) {
+		s.ref(name, size).refs++
+	})
+	s.countContent(meta, 1)
+}
+
+// unlink drops the references of an index entry and returns the blobs that
+// nothing refers to any more. Callers must hold s.mu and delete them.
+func (s *Storage) unlink(meta *FileMeta) []string {
+	s.countContent(meta, -1)
+
+	var unused []string
+
+	meta.eachBlob(func(name string, size int64) {
+		ref := s.ref(name, size)
+		ref.refs--
+
+		if s.forget(name, ref) {
+			unused = append(unused, name)
+		}
+	})
+
+	return unused
+}
+
+// pin keeps a content blob alive while an upload of that content is being
+// committed, and reports whether the blob is already stored. Callers must
+// hold s.mu.
+func (s *Storage) pin(name string, size int64) bool {
+	ref := s.ref(name, size)
+	ref.pins++
+
+	return ref.refs > 0
+}
+
+// unpin releases a pin and returns the blob if nothing refers to it any
+// more. Callers must hold s.mu and delete it.
+func (s *Storage) unpin(name string) []string {
+	ref := s.refs[name]
+	ref.pins--
+
+	if s.forget(name, ref) {
+		return []string{name}
+	}
+
+	return nil
+}
+
+func (s *Storage) ref(name string, size int64) *blobRef {
+	ref := s.refs[name]
+	if ref == nil {
+		ref = &blobRef{size: size}
+		s.refs[name] = ref
+	}
+
+	return ref
+}
+
+func (s *Storage) forget(name string, ref *blobRef) bool {
+	if ref.refs > 0 || ref.pins > 0 {
+		return false
+	}
+
+	delete(s.refs, name)
+
+	return true
+}
+
+// isContentBlob tells content-addressed blobs, named by the hex SHA-256 of
+// their content, from the per-file blobs of older versions of the store.
+func isContentBlob(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(name)
+
+	return err == nil
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func putBlob(t *testing.T, backend Backend, name, content string) {
+	t.Helper()
+
+	w, err := backend.Create(blobKey(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func contentName(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// A content blob nothing refers to may be the only copy of a file whose
+// index entry was lost: it is kept, as a file of its own in the lost-found
+// namespace rather than among files nobody uploaded.
+func TestRebuildQuarantinesOrphanContentBlobs(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{})
+	kept := upload(t, s, DefaultNamespace, "kept.txt", "kept")
+
+	orphan := contentName("orphan")
+	putBlob(t, backend, orphan, "orphan")
+
+	for i := 0; i < 2; i++ {
+		s = newTestStorage(t, backend, Options{})
+
+		if !blobExists(t, backend, orphan) {
+			t.Fatal("orphan content blob was deleted")
+		}
+
+		files, _, err := s.ListFiles(DefaultNamespace, ListOptions{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0].ID != kept.ID {
+			t.Errorf("files after restart = %d, want only %s", len(files), kept.ID)
+		}
+
+		// A second restart must find the blob indexed, not quarantine it
+		// again.
+		lost, _, err := s.ListFiles(LostFoundNamespace, ListOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListFiles(%s): %v", LostFoundNamespace, err)
+		}
+		if len(lost) != 1 || lost[0].Checksum != orphan {
+			t.Fatalf("%s holds %d files, want the orphan blob", LostFoundNamespace, len(lost))
+		}
+		if got := readFile(t, s, LostFoundNamespace, lost[0].ID); got != "orphan" {
+			t.Errorf("quarantined content = %q, want %q", got, "orphan")
+		}
+	}
+}
+
+func remove(t *testing.T, s *Storage, id string) {
+	t.Helper()
+
+	if err := s.Delete(DefaultNamespace, id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlobRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		run  func(t *testing.T, s *Storage)
+		// want tells, by content, whether its blob must still be stored.
+		want map[string]bool
+	}{
+		{
+			name: "shared until the last file is deleted",
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "same")
+				upload(t, s, DefaultNamespace, "b.txt", "same")
+				remove(t, s, a.ID)
+			},
+			want: map[string]bool{"same": true},
+		},
+		{
+			name: "deleted with the last file",
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "same")
+				b := upload(t, s, DefaultNamespace, "b.txt", "same")
+				remove(t, s, a.ID)
+				remove(t, s, b.ID)
+			},
+			want: map[string]bool{"same": false},
+		},
+		{
+			name: "versions of one file sharing content",
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "v1")
+				update(t, s, DefaultNamespace, a.ID, "v2")
+				update(t, s, DefaultNamespace, a.ID, "v1")
+				remove(t, s, a.ID)
+			},
+			want: map[string]bool{"v1": false, "v2": false},
+		},
+		{
+			name: "old version dropped but shared",
+			opts: Options{MaxVersions: 1},
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "v1")
+				upload(t, s, DefaultNamespace, "b.txt", "v1")
+				update(t, s, DefaultNamespace, a.ID, "v2")
+				update(t, s, DefaultNamespace, a.ID, "v3")
+			},
+			want: map[string]bool{"v1": true, "v2": true, "v3": true},
+		},
+		{
+			name: "old version dropped",
+			opts: Options{MaxVersions: 1},
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "v1")
+				update(t, s, DefaultNamespace, a.ID, "v2")
+				update(t, s, DefaultNamespace, a.ID, "v3")
+			},
+			want: map[string]bool{"v1": false, "v2": true, "v3": true},
+		},
+		{
+			name: "kept by the trash",
+			opts: Options{TrashRetention: time.Hour},
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "same")
+				remove(t, s, a.ID)
+			},
+			want: map[string]bool{"same": true},
+		},
+		{
+			name: "freed by purging the trash",
+			opts: Options{TrashRetention: time.Hour},
+			run: func(t *testing.T, s *Storage) {
+				a := upload(t, s, DefaultNamespace, "a.txt", "same")
+				b := upload(t, s, DefaultNamespace, "b.txt", "same")
+				remove(t, s, a.ID)
+				remove(t, s, b.ID)
+				if _, err := s.PurgeTrash(time.Now().Add(2 * time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]bool{"same": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemory()
+			s := newTestStorage(t, backend, tt.opts)
+
+			tt.run(t, s)
+
+			for content, want := range tt.want {
+				if got := blobExists(t, backend, contentName(content)); got != want {
+					t.Errorf("blob of %q stored = %v, want %v", content, got, want)
+				}
+			}
+
+			// The counts kept since startup must agree with those derived
+			// from the index on the next one.
+			if got, want := s.DedupStats(), newTestStorage(t, backend, tt.opts).DedupStats(); got != want {
+				t.Errorf("dedup stats = %+v, after restart %+v", got, want)
+			}
+		})
+	}
+}
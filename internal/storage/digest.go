@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrContentNotFound = errors.New("content not found")
+
+// CreateFromDigest creates a file, or the next version of fileID if it is
+// set, from content the namespace already stores, so nothing has to be
+// uploaded again. The content is identified by its hex SHA-256 and size.
+//
+// Only content of the namespace's own files is found. Matching content in
+// other namespaces is ignored: otherwise anyone could probe which content is
+// stored elsewhere, or take a copy of it knowing nothing but its digest.
+func (s *Storage) CreateFromDigest(namespace, checksum string, size int64, fileName, fileID string, expiresAt time.Time) (*FileMeta, error) {
+	checksum = strings.ToLower(checksum)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return nil, ErrNamespaceNotFound
+	}
+
+	var current *FileMeta
+	if fileID != "" {
+		meta, ok := s.files[fileID]
+		if !ok || meta.Namespace != namespace {
+			return nil, ErrNotFound
+		}
+		current = meta
+	}
+
+	source := s.findContent(namespace, checksum, size)
+	if source == nil {
+		return nil, ErrContentNotFound
+	}
+
+	u := s.usageOf(namespace)
+	if current == nil {
+		if err := s.checkFiles(namespace, u, 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkBytes(namespace, u, size); err != nil {
+		return nil, err
+	}
+
+	if fileName == "" && current != nil {
+		fileName = current.Name
+	}
+
+	v := *source
+	v.Version = 1
+	v.CreatedAt = time.Now()
+	if ct := mime.TypeByExtension(path.Ext(fileName)); ct != "" {
+		v.ContentType = ct
+	}
+
+	var meta *FileMeta
+	if current == nil {
+		meta = newFileMeta(uuid.NewString(), &v)
+		meta.Namespace = namespace
+		meta.Name = fileName
+		meta.ExpiresAt = expiresAt
+	} else {
+		meta = current.withVersion(&v, fileName, s.maxVersions)
+		if !expiresAt.IsZero() {
+			meta.ExpiresAt = expiresAt
+		}
+	}
+
+	unused, err := s.replace(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteBlobs(unused)
+
+	return meta, nil
+}
+
+// contentKey is content as CreateFromDigest asks for it: within one
+// namespace, by hex SHA-256 and size.
+type contentKey struct {
+	namespace string
+	checksum  string
+	size      int64
+}
+
+// contentRef counts the versions of a namespace's files stored in one blob,
+// and keeps one of them to copy.
+type contentRef struct {
+	versions int
+	version  *Version
+}
+
+// countContent adds n to the content count of every version of meta. Trashed
+// files are not counted: CreateFromDigest must not find their content.
+// Callers must hold s.mu.
+func (s *Storage) countContent(meta *FileMeta, n int) {
+	if !meta.DeletedAt.IsZero() {
+		return
+	}
+
+	for _, v := range append([]*Version{meta.current()}, meta.History...) {
+		key := contentKey{namespace: meta.Namespace, checksum: v.Checksum, size: v.Size}
+
+		blobs := s.contents[key]
+		if blobs == nil {
+			blobs = make(map[string]*contentRef)
+			s.contents[key] = blobs
+		}
+
+		ref := blobs[v.Blob]
+		if ref == nil {
+			ref = &contentRef{version: v}
+			blobs[v.Blob] = ref
+		}
+
+		ref.versions += n
+		if ref.versions > 0 {
+			continue
+		}
+
+		delete(blobs, v.Blob)
+		if len(blobs) == 0 {
+			delete(s.contents, key)
+		}
+	}
+}
+
+// findContent looks for a version of a namespace's file with the given
+// content. Callers must hold s.mu.
+func (s *Storage) findContent(namespace, checksum string, size int64) *Version {
+	for _, ref := range s.contents[contentKey{namespace: namespace, checksum: checksum, size: size}] {
+		return ref.version
+	}
+
+	return nil
+}
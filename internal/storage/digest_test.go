@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateFromDigest(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare changes the store holding file, uploaded with "hello" to
+		// team-a, before "hello" is looked up in team-a.
+		prepare func(t *testing.T, s *Storage, file *FileMeta)
+		restart bool
+		want    error
+	}{
+		{
+			name:    "current version",
+			prepare: func(*testing.T, *Storage, *FileMeta) {},
+		},
+		{
+			name: "previous version",
+			prepare: func(t *testing.T, s *Storage, file *FileMeta) {
+				w, err := s.UpdateFile("team-a", file.ID, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := w.Write([]byte("bye")); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "trashed",
+			prepare: func(t *testing.T, s *Storage, file *FileMeta) {
+				if err := s.Delete("team-a", file.ID); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrContentNotFound,
+		},
+		{
+			name: "restored",
+			prepare: func(t *testing.T, s *Storage, file *FileMeta) {
+				if err := s.Delete("team-a", file.ID); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := s.Restore("team-a", file.ID); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "purged",
+			prepare: func(t *testing.T, s *Storage, file *FileMeta) {
+				if err := s.Delete("team-a", file.ID); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := s.PurgeTrash(time.Now().Add(2 * time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrContentNotFound,
+		},
+		{
+			name: "only in another namespace",
+			prepare: func(t *testing.T, s *Storage, file *FileMeta) {
+				upload(t, s, "team-b", "b.txt", "hello")
+				if err := s.Delete("team-a", file.ID); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: ErrContentNotFound,
+		},
+		{
+			name:    "after restart",
+			prepare: func(*testing.T, *Storage, *FileMeta) {},
+			restart: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemory()
+			s := newTestStorage(t, backend, Options{TrashRetention: time.Hour})
+			for _, name := range []string{"team-a", "team-b"} {
+				if _, err := s.CreateNamespace(name, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file := upload(t, s, "team-a", "a.txt", "hello")
+
+			tt.prepare(t, s, file)
+			if tt.restart {
+				s = newTestStorage(t, backend, Options{TrashRetention: time.Hour})
+			}
+
+			meta, err := s.CreateFromDigest("team-a", file.Checksum, file.Size, "copy.txt", "", time.Time{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateFromDigest = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := readFile(t, s, "team-a", meta.ID); got != "hello" {
+				t.Errorf("copy content = %q, want %q", got, "hello")
+			}
+		})
+	}
+}
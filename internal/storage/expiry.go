@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ExpireAt makes the uploaded file expire at t. The zero time leaves a new
+// file without expiry and keeps the current expiry of an updated one.
+func (w *Writer) ExpireAt(t time.Time) {
+	w.meta.ExpiresAt = t
+}
+
+// Expire permanently removes every file that expired before now, with all
+// its versions. Expired files skip the trash: they were meant to go away. It
+// returns how many files were removed and how many bytes that freed; content
+// still shared with other files frees nothing.
+func (s *Storage) Expire(now time.Time) (int, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*FileMeta
+	for _, meta := range s.files {
+		if !meta.ExpiresAt.IsZero() && meta.ExpiresAt.Before(now) {
+			expired = append(expired, meta)
+		}
+	}
+
+	if len(expired) == 0 {
+		return 0, 0, nil
+	}
+
+	for _, meta := range expired {
+		delete(s.files, meta.ID)
+		s.account(meta, -1)
+	}
+
+	if err := s.save(fileIDs(expired)...); err != nil {
+		for _, meta := range expired {
+			s.files[meta.ID] = meta
+			s.account(meta, 1)
+		}
+		return 0, 0, err
+	}
+
+	var reclaimed int64
+	var errs []error
+
+	for _, meta := range expired {
+		unused := s.unlink(meta)
+		reclaimed += blobsSize(meta, unused)
+
+		if err := s.deleteBlobs(unused); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return len(expired), reclaimed, errors.Join(errs...)
+}
+
+// blobsSize is the total size of those of meta's blobs that are in names.
+func blobsSize(meta *FileMeta, names []string) int64 {
+	var size int64
+
+	meta.eachBlob(func(name string, n int64) {
+		if slices.Contains(names, name) {
+			size += n
+		}
+	})
+
+	return size
+}
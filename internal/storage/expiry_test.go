@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func uploadExpiring(t *testing.T, s *Storage, name, content string, expiresAt time.Time) *FileMeta {
+	t.Helper()
+
+	w, err := s.CreateFile(DefaultNamespace, name)
+	if err != nil {
+		t.Fatalf("CreateFile(%s): %v", name, err)
+	}
+	w.ExpireAt(expiresAt)
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	meta, err := s.FindFileByID(DefaultNamespace, w.ID())
+	if err != nil {
+		t.Fatalf("FindFileByID after commit: %v", err)
+	}
+
+	return meta
+}
+
+func TestExpire(t *testing.T) {
+	backend := NewMemory()
+	// Expired files skip the trash even when it is enabled.
+	opts := Options{TrashRetention: time.Hour}
+	s := newTestStorage(t, backend, opts)
+
+	now := time.Now()
+	expired := uploadExpiring(t, s, "old.txt", "old", now.Add(time.Minute))
+	expired = update(t, s, DefaultNamespace, expired.ID, "older")
+	alive := uploadExpiring(t, s, "new.txt", "new", now.Add(time.Hour))
+	forever := upload(t, s, DefaultNamespace, "forever.txt", "forever")
+
+	// An update without an expiry of its own keeps the file's one.
+	if !expired.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expiry after update = %v, want %v", expired.ExpiresAt, now.Add(time.Minute))
+	}
+
+	n, reclaimed, err := s.Expire(now.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+	if n != 1 || reclaimed != int64(len("old")+len("older")) {
+		t.Errorf("Expire = %d files, %d bytes, want 1, %d", n, reclaimed, len("old")+len("older"))
+	}
+
+	for _, v := range expired.History {
+		if blobExists(t, backend, v.Blob) {
+			t.Errorf("blob of expired version %d is still stored", v.Version)
+		}
+	}
+	if trash, _ := s.Trash(DefaultNamespace); len(trash) != 0 {
+		t.Errorf("expired file went to the trash")
+	}
+	if u := usage(t, s, DefaultNamespace); u.Files != 2 {
+		t.Errorf("usage after Expire = %d files, want 2", u.Files)
+	}
+
+	s = newTestStorage(t, backend, opts)
+	if _, err := s.FindFileByID(DefaultNamespace, expired.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired file after restart: %v, want ErrNotFound", err)
+	}
+	for _, meta := range []*FileMeta{alive, forever} {
+		if _, err := s.FindFileByID(DefaultNamespace, meta.ID); err != nil {
+			t.Errorf("%s lost: %v", meta.Name, err)
+		}
+	}
+}
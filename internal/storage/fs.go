@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stagingDir holds blobs that are still being written. It is the only
+// directory List skips, so nothing in it is visible as a key.
+const stagingDir = ".staging"
+
+type FSBackend struct {
+	root string
+}
+
+func NewFS(root string) (*FSBackend, error) {
+	if err := os.MkdirAll(filepath.Join(root, stagingDir), 0755); err != nil {
+		return nil, err
+	}
+
+	b := &FSBackend{root: root}
+
+	if err := b.sweep(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *FSBackend) Create(key string) (BlobWriter, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.CreateTemp(filepath.Join(b.root, stagingDir), "blob-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsWriter{File: file, path: path}, nil
+}
+
+func (b *FSBackend) Open(key string) (io.ReadSeekCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (b *FSBackend) Stat(key string) (BlobInfo, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return BlobInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return BlobInfo{}, err
+	}
+
+	return BlobInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (b *FSBackend) List(prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+
+	err := filepath.WalkDir(b.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == filepath.Join(b.root, stagingDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		blobs = append(blobs, BlobInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+
+		return nil
+	})
+
+	return blobs, err
+}
+
+func (b *FSBackend) Delete(key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (b *FSBackend) Rename(from, to string) error {
+	fromPath, err := b.path(from)
+	if err != nil {
+		return err
+	}
+
+	toPath, err := b.path(to)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(toPath), 0755); err != nil {
+		return err
+	}
+
+	err = os.Rename(fromPath, toPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(toPath))
+}
+
+func (b *FSBackend) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", errors.New("invalid key: " + key)
+	}
+
+	return filepath.Join(b.root, filepath.FromSlash(key)), nil
+}
+
+// sweep removes whatever a previous process left half-written in the
+// staging directory. It only runs before any writer exists.
+func (b *FSBackend) sweep() error {
+	staging := filepath.Join(b.root, stagingDir)
+
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(staging, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fsWriter writes into the staging directory and on Close fsyncs the data,
+// renames it over the destination and fsyncs the destination directory, so
+// after a crash a key either has its complete content or does not exist.
+type fsWriter struct {
+	*os.File
+	path string
+}
+
+func (w *fsWriter) Close() error {
+	if err := w.commit(); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return syncDir(filepath.Dir(w.path))
+}
+
+func (w *fsWriter) commit() error {
+	if err := w.File.Chmod(0644); err != nil {
+		return err
+	}
+
+	if err := w.File.Sync(); err != nil {
+		return err
+	}
+
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(w.Name(), w.path)
+}
+
+func (w *fsWriter) Abort() error {
+	w.File.Close()
+
+	err := os.Remove(w.Name())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// journalPrefix holds the changes made to the index since index.json was
+// last written, one record per change, named by a sequence number so that
+// they replay in order.
+const journalPrefix = "journal/"
+
+// journalRecord is one change of the index: the entries of the files it
+// touched as they are after it, trashed ones included, and the IDs of those
+// that are gone.
+type journalRecord struct {
+	Files   []*FileMeta `json:"files,omitempty"`
+	Deleted []string    `json:"deleted,omitempty"`
+}
+
+func journalKey(seq uint64) string {
+	return fmt.Sprintf("%s%020d.json", journalPrefix, seq)
+}
+
+func fileIDs(list []*FileMeta) []string {
+	ids := make([]string, len(list))
+	for i, meta := range list {
+		ids[i] = meta.ID
+	}
+
+	return ids
+}
+
+func journalSeq(key string) (uint64, bool) {
+	name, ok := strings.CutPrefix(key, journalPrefix)
+	if !ok {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+
+	return seq, err == nil
+}
+
+// save persists the change of the files with the given IDs, whose index
+// entries the caller has already updated, as the next journal record. Only
+// those entries are written, so the cost of a change does not grow with the
+// index. Callers must hold s.mu.
+func (s *Storage) save(ids ...string) error {
+	var record journalRecord
+	for _, id := range ids {
+		if meta := s.files[id]; meta != nil {
+			record.Files = append(record.Files, meta)
+		} else if meta := s.trash[id]; meta != nil {
+			record.Files = append(record.Files, meta)
+		} else {
+			record.Deleted = append(record.Deleted, id)
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := s.write(journalKey(s.seq+1), data); err != nil {
+		return err
+	}
+
+	s.seq++
+
+	return nil
+}
+
+// replay applies the journal records left since index.json was written.
+func (s *Storage) replay() error {
+	infos, err := s.backend.List(journalPrefix)
+	if err != nil {
+		return err
+	}
+
+	var seqs []uint64
+	for _, info := range infos {
+		if seq, ok := journalSeq(info.Key); ok {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] < seqs[j]
+	})
+
+	if len(seqs) > 0 {
+		s.pruned = seqs[0] - 1
+	}
+
+	for _, seq := range seqs {
+		record, err := s.readRecord(journalKey(seq))
+		if err != nil {
+			return fmt.Errorf("journal record %d: %w", seq, err)
+		}
+
+		for _, meta := range record.Files {
+			s.put(meta)
+		}
+		for _, id := range record.Deleted {
+			delete(s.files, id)
+			delete(s.trash, id)
+		}
+
+		s.seq = seq
+	}
+
+	return nil
+}
+
+func (s *Storage) readRecord(key string) (*journalRecord, error) {
+	r, err := s.backend.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var record journalRecord
+	if err := json.NewDecoder(r).Decode(&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Compact writes the whole index to index.json and drops the journal
+// records it now covers, so they are not replayed on the next start. Only
+// taking the snapshot holds the lock; it is encoded and written without it,
+// entries never being modified in place.
+func (s *Storage) Compact() error {
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	s.mu.RLock()
+	if s.seq == s.compacted {
+		s.mu.RUnlock()
+		return nil
+	}
+	list, seq := s.sorted(), s.seq
+	s.mu.RUnlock()
+
+	return s.compact(list, seq)
+}
+
+// compact writes list, the index as of journal record seq, to index.json
+// and deletes the records up to seq. Callers must hold s.compactMu or be
+// the only user of s.
+func (s *Storage) compact(list []*FileMeta, seq uint64) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := s.write(indexKey, data); err != nil {
+		return err
+	}
+
+	s.compacted = seq
+
+	// Records are deleted oldest first and the first failure stops it: the
+	// ones left behind are then the latest of each file they touch, and
+	// replaying them over the new index changes nothing.
+	for s.pruned < seq {
+		if err := s.backend.Delete(journalKey(s.pruned + 1)); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		s.pruned++
+	}
+
+	return nil
+}
+
+func (s *Storage) write(key string, data []byte) error {
+	w, err := s.backend.Create(key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
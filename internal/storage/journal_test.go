@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// state is what a restart must preserve: every file by ID.
+func state(s *Storage) map[string]FileMeta {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	got := make(map[string]FileMeta)
+	for id, meta := range s.files {
+		got[id] = *meta
+	}
+
+	return got
+}
+
+func journalKeys(t *testing.T, backend Backend) []string {
+	t.Helper()
+
+	infos, err := backend.List(journalPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, info := range infos {
+		keys = append(keys, info.Key)
+	}
+
+	return keys
+}
+
+func TestJournal(t *testing.T) {
+	tests := []struct {
+		name string
+		// between runs after the changes, before the restart.
+		between func(t *testing.T, s *Storage, backend Backend)
+		// journaled is whether records must be left for the restart.
+		journaled bool
+	}{
+		{
+			name:      "replayed",
+			between:   func(*testing.T, *Storage, Backend) {},
+			journaled: true,
+		},
+		{
+			name: "compacted",
+			between: func(t *testing.T, s *Storage, _ Backend) {
+				if err := s.Compact(); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			// A compaction that failed to delete its records leaves the
+			// latest ones behind, replayed over an index holding them.
+			name: "stale record after compaction",
+			between: func(t *testing.T, s *Storage, backend Backend) {
+				key := journalKey(s.seq)
+				r, err := backend.Open(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := io.ReadAll(r)
+				r.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := s.Compact(); err != nil {
+					t.Fatal(err)
+				}
+
+				w, err := backend.Create(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := w.Write(data); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			journaled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemory()
+			s := newTestStorage(t, backend, Options{})
+			for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+				upload(t, s, DefaultNamespace, name, name)
+			}
+			want := state(s)
+
+			tt.between(t, s, backend)
+			if got := len(journalKeys(t, backend)) > 0; got != tt.journaled {
+				t.Fatalf("journal records left = %v, want %v", got, tt.journaled)
+			}
+
+			s = newTestStorage(t, backend, Options{})
+
+			got := state(s)
+			if len(got) != len(want) {
+				t.Errorf("files after restart = %d, want %d", len(got), len(want))
+			}
+			for id, w := range want {
+				if g, ok := got[id]; !ok || g.Name != w.Name || g.Checksum != w.Checksum {
+					t.Errorf("file %s after restart = %+v, want %+v", id, g, w)
+				}
+			}
+
+			// Starting up compacts what it replayed.
+			if keys := journalKeys(t, backend); len(keys) > 0 {
+				t.Errorf("journal records after start = %v, want none", keys)
+			}
+		})
+	}
+}
+
+func TestCompactWithoutChanges(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{})
+	upload(t, s, DefaultNamespace, "a.txt", "hello")
+
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Delete(indexKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing changed since the last compaction, so the index is not
+	// written again.
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Stat(indexKey); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(index) = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"cmp"
+	"container/heap"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid page token")
+
+type ListOrder int
+
+const (
+	OrderCreatedAt ListOrder = iota
+	OrderName
+	OrderSize
+)
+
+// ListFilter narrows a listing. Zero fields do not filter.
+type ListFilter struct {
+	NamePrefix    string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	MinSize       int64
+	MaxSize       int64
+}
+
+func (f *ListFilter) match(meta *FileMeta) bool {
+	if !strings.HasPrefix(meta.Name, f.NamePrefix) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !meta.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !meta.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if meta.Size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && meta.Size > f.MaxSize {
+		return false
+	}
+
+	return true
+}
+
+type ListOptions struct {
+	Order  ListOrder
+	Desc   bool
+	Filter ListFilter
+	// After continues a previous listing: only files ordered after it are
+	// returned. It must have been produced with the same Order and Desc.
+	After *Cursor
+	Limit int
+}
+
+// less reports whether a is listed before b.
+func (o *ListOptions) less(a, b *FileMeta) bool {
+	c := compareFiles(o.Order, a, b)
+	if o.Desc {
+		c = -c
+	}
+
+	return c < 0
+}
+
+// Cursor is the position of the last file of a page. It holds the sort key
+// rather than an offset, so pages stay consistent while files are added or
+// deleted between calls.
+type Cursor struct {
+	Order     ListOrder `json:"o"`
+	Desc      bool      `json:"d,omitempty"`
+	ID        string    `json:"i"`
+	Name      string    `json:"n,omitempty"`
+	CreatedAt time.Time `json:"c,omitzero"`
+	Size      int64     `json:"s,omitempty"`
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// ListFiles returns one page of a namespace in the requested order, and the
+// cursor of the next page, nil on the last one. Only the page itself is kept
+// in memory: matching files go through a heap bounded by the page size
+// instead of the whole namespace being copied and sorted.
+func (s *Storage) ListFiles(namespace string, opts ListOptions) ([]*FileMeta, *Cursor, error) {
+	if opts.Limit <= 0 {
+		return nil, nil, errors.New("list limit must be positive")
+	}
+
+	if c := opts.After; c != nil && (c.Order != opts.Order || c.Desc != opts.Desc) {
+		return nil, nil, ErrInvalidCursor
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return nil, nil, ErrNamespaceNotFound
+	}
+
+	less := opts.less
+
+	var after *FileMeta
+	if opts.After != nil {
+		after = opts.After.position()
+	}
+
+	// One extra file tells whether there is a next page.
+	page := &fileHeap{less: less}
+	limit := opts.Limit + 1
+
+	for _, meta := range s.files {
+		if meta.Namespace != namespace || !opts.Filter.match(meta) {
+			continue
+		}
+		if after != nil && !less(after, meta) {
+			continue
+		}
+
+		if page.Len() < limit {
+			heap.Push(page, meta)
+		} else if less(meta, page.files[0]) {
+			page.files[0] = meta
+			heap.Fix(page, 0)
+		}
+	}
+
+	files := page.files
+	sort.Slice(files, func(i, j int) bool {
+		return less(files[i], files[j])
+	})
+
+	if len(files) <= opts.Limit {
+		return files, nil, nil
+	}
+
+	files = files[:opts.Limit]
+	last := files[len(files)-1]
+
+	next := &Cursor{
+		Order:     opts.Order,
+		Desc:      opts.Desc,
+		ID:        last.ID,
+		Name:      last.Name,
+		CreatedAt: last.CreatedAt,
+		Size:      last.Size,
+	}
+
+	return files, next, nil
+}
+
+// Walk calls fn for every file ListFiles would return across all pages, in
+// the same order. The matching files are collected and sorted once, and the
+// index is only locked while they are collected, so a slow fn never blocks
+// uploads. The walk sees the namespace as it was when it started.
+func (s *Storage) Walk(namespace string, opts ListOptions, fn func(*FileMeta) error) error {
+	if c := opts.After; c != nil && (c.Order != opts.Order || c.Desc != opts.Desc) {
+		return ErrInvalidCursor
+	}
+
+	s.mu.RLock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		s.mu.RUnlock()
+		return ErrNamespaceNotFound
+	}
+
+	var files []*FileMeta
+	for _, meta := range s.files {
+		if meta.Namespace == namespace && opts.Filter.match(meta) {
+			files = append(files, meta)
+		}
+	}
+
+	s.mu.RUnlock()
+
+	less := opts.less
+
+	sort.Slice(files, func(i, j int) bool {
+		return less(files[i], files[j])
+	})
+
+	if opts.After != nil {
+		after := opts.After.position()
+		files = files[sort.Search(len(files), func(i int) bool {
+			return less(after, files[i])
+		}):]
+	}
+
+	for _, meta := range files {
+		if err := fn(meta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cursor) position() *FileMeta {
+	return &FileMeta{ID: c.ID, Name: c.Name, CreatedAt: c.CreatedAt, Size: c.Size}
+}
+
+// compareFiles orders by the requested key and breaks ties by ID, so the
+// order is total and a cursor always points to a single place.
+func compareFiles(order ListOrder, a, b *FileMeta) int {
+	var c int
+
+	switch order {
+	case OrderName:
+		c = strings.Compare(a.Name, b.Name)
+
+	case OrderSize:
+		c = cmp.Compare(a.Size, b.Size)
+
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+
+	if c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.ID, b.ID)
+}
+
+// fileHeap keeps the page with its last file on top, so a file that sorts
+// before it can replace it in O(log n).
+type fileHeap struct {
+	files []*FileMeta
+	less  func(a, b *FileMeta) bool
+}
+
+func (h *fileHeap) Len() int           { return len(h.files) }
+func (h *fileHeap) Less(i, j int) bool { return h.less(h.files[j], h.files[i]) }
+func (h *fileHeap) Swap(i, j int)      { h.files[i], h.files[j] = h.files[j], h.files[i] }
+func (h *fileHeap) Push(x any)         { h.files = append(h.files, x.(*FileMeta)) }
+
+func (h *fileHeap) Pop() any {
+	last := h.files[len(h.files)-1]
+	h.files = h.files[:len(h.files)-1]
+
+	return last
+}
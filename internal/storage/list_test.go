@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// listAll pages through a namespace, passing every cursor through its
+// encoded form as a client would, and returns the names in order.
+func listAll(t *testing.T, s *Storage, opts ListOptions) []string {
+	t.Helper()
+
+	var names []string
+	for {
+		files, next, err := s.ListFiles(DefaultNamespace, opts)
+		if err != nil {
+			t.Fatalf("ListFiles: %v", err)
+		}
+		if len(files) > opts.Limit {
+			t.Fatalf("page of %d files, limit %d", len(files), opts.Limit)
+		}
+
+		for _, meta := range files {
+			names = append(names, meta.Name)
+		}
+
+		if next == nil {
+			return names
+		}
+
+		opts.After, err = DecodeCursor(next.Encode())
+		if err != nil {
+			t.Fatalf("DecodeCursor: %v", err)
+		}
+	}
+}
+
+func TestListFilesPaging(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+
+	// Uploaded in creation order c, a, b, with sizes a < c < b; d and e
+	// have the same size, so only their IDs order them by size.
+	for _, f := range []struct{ name, content string }{
+		{"c", "cc"},
+		{"a", "a"},
+		{"b", "bbbb"},
+		{"d", "ddd"},
+		{"e", "eee"},
+	} {
+		upload(t, s, DefaultNamespace, f.name, f.content)
+	}
+
+	bySize := []string{"a", "c", "d", "e", "b"}
+	if idOf(t, s, "d") > idOf(t, s, "e") {
+		bySize = []string{"a", "c", "e", "d", "b"}
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"created", ListOptions{Order: OrderCreatedAt}, []string{"c", "a", "b", "d", "e"}},
+		{"created desc", ListOptions{Order: OrderCreatedAt, Desc: true}, []string{"e", "d", "b", "a", "c"}},
+		{"name", ListOptions{Order: OrderName}, []string{"a", "b", "c", "d", "e"}},
+		{"size", ListOptions{Order: OrderSize}, bySize},
+		{"filtered", ListOptions{Order: OrderName, Filter: ListFilter{MinSize: 3}}, []string{"b", "d", "e"}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 5, 10} {
+			t.Run(fmt.Sprintf("%s/limit %d", tt.name, limit), func(t *testing.T) {
+				tt.opts.Limit = limit
+				if got := listAll(t, s, tt.opts); !slices.Equal(got, tt.want) {
+					t.Errorf("names = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func idOf(t *testing.T, s *Storage, name string) string {
+	t.Helper()
+
+	for id, meta := range s.files {
+		if meta.Name == name {
+			return id
+		}
+	}
+	t.Fatalf("no file %s", name)
+
+	return ""
+}
+
+// A cursor holds the position rather than an offset, so changes between
+// pages neither repeat nor skip files that were there all along.
+func TestListFilesCursorSurvivesChanges(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	for _, name := range []string{"b", "d", "f"} {
+		upload(t, s, DefaultNamespace, name, name)
+	}
+
+	opts := ListOptions{Order: OrderName, Limit: 1}
+	first, next, err := s.ListFiles(DefaultNamespace, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Before the cursor, after it, and the file the cursor points at.
+	upload(t, s, DefaultNamespace, "a", "a")
+	upload(t, s, DefaultNamespace, "e", "e")
+	if err := s.Delete(DefaultNamespace, first[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.After = next
+	opts.Limit = 10
+	rest, _, err := s.ListFiles(DefaultNamespace, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, meta := range rest {
+		got = append(got, meta.Name)
+	}
+	if want := []string{"d", "e", "f"}; !slices.Equal(got, want) {
+		t.Errorf("next page = %v, want %v", got, want)
+	}
+}
+
+func TestListFilesInvalidCursor(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	upload(t, s, DefaultNamespace, "a", "a")
+	upload(t, s, DefaultNamespace, "b", "b")
+
+	_, next, err := s.ListFiles(DefaultNamespace, ListOptions{Order: OrderName, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		opts  ListOptions
+	}{
+		{"not base64", "%%%", ListOptions{Order: OrderName}},
+		{"not json", "bm9wZQ", ListOptions{Order: OrderName}},
+		{"no id", (&Cursor{Order: OrderName}).Encode(), ListOptions{Order: OrderName}},
+		{"other order", next.Encode(), ListOptions{Order: OrderSize}},
+		{"other direction", next.Encode(), ListOptions{Order: OrderName, Desc: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.token)
+			if err == nil {
+				tt.opts.After = cursor
+				tt.opts.Limit = 1
+				_, _, err = s.ListFiles(DefaultNamespace, tt.opts)
+			}
+
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func walkAll(t *testing.T, s *Storage, opts ListOptions) []string {
+	t.Helper()
+
+	var names []string
+	err := s.Walk(DefaultNamespace, opts, func(meta *FileMeta) error {
+		names = append(names, meta.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+
+	return names
+}
+
+func TestWalk(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	for i := range 25 {
+		upload(t, s, DefaultNamespace, fmt.Sprintf("f%02d", (i*7)%25), strings.Repeat("x", i%4))
+	}
+
+	for _, opts := range []ListOptions{
+		{Order: OrderCreatedAt},
+		{Order: OrderName, Desc: true},
+		{Order: OrderSize},
+		{Order: OrderName, Filter: ListFilter{MinSize: 2}},
+	} {
+		opts.Limit = 4
+		want := listAll(t, s, opts)
+
+		if got := walkAll(t, s, opts); !slices.Equal(got, want) {
+			t.Errorf("Walk(%+v) = %v, want %v", opts, got, want)
+		}
+
+		// A page token from List resumes the walk right after that page.
+		_, next, err := s.ListFiles(DefaultNamespace, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.After = next
+		if got := walkAll(t, s, opts); !slices.Equal(got, want[4:]) {
+			t.Errorf("Walk(%+v) after the first page = %v, want %v", opts, got, want[4:])
+		}
+	}
+}
+
+// fn runs without the index locked, so it may change the namespace; the walk
+// still goes over the files that were there when it started.
+func TestWalkUnlocked(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	for _, name := range []string{"a", "b", "c"} {
+		upload(t, s, DefaultNamespace, name, name)
+	}
+
+	var names []string
+	err := s.Walk(DefaultNamespace, ListOptions{Order: OrderName}, func(meta *FileMeta) error {
+		names = append(names, meta.Name)
+		if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+			return err
+		}
+		upload(t, s, DefaultNamespace, "z"+meta.Name, meta.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(names, want) {
+		t.Errorf("walked %v, want %v", names, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = s.Walk(DefaultNamespace, ListOptions{}, func(*FileMeta) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Walk = %v after %d calls, want the error of fn after 1", err, calls)
+	}
+
+	if err := s.Walk("nope", ListOptions{}, nil); !errors.Is(err, ErrNamespaceNotFound) {
+		t.Errorf("Walk of an unknown namespace = %v, want ErrNamespaceNotFound", err)
+	}
+}
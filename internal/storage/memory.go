@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MemoryBackend struct {
+	mu    sync.RWMutex
+	blobs map[string]*memoryBlob
+}
+
+type memoryBlob struct {
+	data    []byte
+	modTime time.Time
+}
+
+func NewMemory() *MemoryBackend {
+	return &MemoryBackend{blobs: make(map[string]*memoryBlob)}
+}
+
+func (b *MemoryBackend) Create(key string) (BlobWriter, error) {
+	return &memoryWriter{backend: b, key: key}, nil
+}
+
+func (b *MemoryBackend) Open(key string) (io.ReadSeekCloser, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blob, ok := b.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return nopCloser{bytes.NewReader(blob.data)}, nil
+}
+
+func (b *MemoryBackend) Stat(key string) (BlobInfo, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blob, ok := b.blobs[key]
+	if !ok {
+		return BlobInfo{}, ErrNotFound
+	}
+
+	return BlobInfo{Key: key, Size: int64(len(blob.data)), ModTime: blob.modTime}, nil
+}
+
+func (b *MemoryBackend) List(prefix string) ([]BlobInfo, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var blobs []BlobInfo
+	for key, blob := range b.blobs {
+		if strings.HasPrefix(key, prefix) {
+			blobs = append(blobs, BlobInfo{Key: key, Size: int64(len(blob.data)), ModTime: blob.modTime})
+		}
+	}
+
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Key < blobs[j].Key })
+
+	return blobs, nil
+}
+
+func (b *MemoryBackend) Delete(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.blobs[key]; !ok {
+		return ErrNotFound
+	}
+	delete(b.blobs, key)
+
+	return nil
+}
+
+func (b *MemoryBackend) Rename(from, to string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, ok := b.blobs[from]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(b.blobs, from)
+	b.blobs[to] = blob
+
+	return nil
+}
+
+type memoryWriter struct {
+	backend *MemoryBackend
+	key     string
+	buf     bytes.Buffer
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	w.backend.mu.Lock()
+	defer w.backend.mu.Unlock()
+
+	w.backend.blobs[w.key] = &memoryBlob{data: w.buf.Bytes(), modTime: time.Now()}
+
+	return nil
+}
+
+func (w *memoryWriter) Abort() error {
+	w.buf.Reset()
+
+	return nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
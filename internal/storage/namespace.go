@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const namespacesKey = "namespaces.json"
+
+// LostFoundNamespace receives the content blobs that no index entry refers
+// to on startup, each as a file of its own. Such a blob is usually garbage
+// left by a crash, but it may be the only copy of a file whose index entry
+// was lost, so it is kept for an operator to look at instead of deleted.
+const LostFoundNamespace = "lost-found"
+
+var (
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	ErrNamespaceExists   = errors.New("namespace already exists")
+	ErrNamespaceNotEmpty = errors.New("namespace is not empty")
+	ErrNamespaceReserved = errors.New("default namespace cannot be deleted")
+	ErrInvalidNamespace  = errors.New("namespace name must be 1-63 lowercase letters, digits, '.', '_' or '-'")
+	ErrInvalidQuota      = errors.New("quota limits cannot be negative")
+)
+
+var namespaceName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+// Namespace isolates one team's files from everyone else's. Blobs are shared
+// by all namespaces; isolation is enforced through the index, so a file can
+// only be listed, read or deleted through the namespace it was uploaded to.
+type Namespace struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// Quota replaces the default quota for this namespace. Nil keeps the
+	// default.
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// CreateNamespace registers a namespace. A nil quota leaves it on the
+// default one.
+func (s *Storage) CreateNamespace(name string, quota *Quota) (*Namespace, error) {
+	if !namespaceName.MatchString(name) {
+		return nil, ErrInvalidNamespace
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.namespaces[name]; ok {
+		return nil, ErrNamespaceExists
+	}
+
+	if quota != nil && (quota.MaxBytes < 0 || quota.MaxFiles < 0) {
+		return nil, ErrInvalidQuota
+	}
+
+	ns := &Namespace{Name: name, CreatedAt: time.Now(), Quota: quota}
+	s.namespaces[name] = ns
+
+	if err := s.saveNamespaces(); err != nil {
+		delete(s.namespaces, name)
+		return nil, err
+	}
+
+	return ns, nil
+}
+
+// DeleteNamespace removes an empty namespace. Uploads still in progress count
+// as content, so a namespace cannot disappear under a running upload. Files
+// in its trash are purged for good: a namespace created later under the same
+// name must not see them.
+func (s *Storage) DeleteNamespace(name string) error {
+	if name == DefaultNamespace {
+		return ErrNamespaceReserved
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ns, ok := s.namespaces[name]
+	if !ok {
+		return ErrNamespaceNotFound
+	}
+
+	u := s.usageOf(name)
+	if u.Files+u.PendingFiles > 0 {
+		return ErrNamespaceNotEmpty
+	}
+
+	var trashed []*FileMeta
+	for _, meta := range s.trash {
+		if meta.Namespace == name {
+			trashed = append(trashed, meta)
+		}
+	}
+
+	// The trash goes first: if the namespace outlived it, it would only be
+	// empty, while trashed files outliving the namespace would bring it back
+	// on the next start.
+	// Blobs that could not be deleted are only left behind, so only an
+	// index that could not be saved stops the deletion.
+	if len(trashed) > 0 {
+		if n, err := s.purge(trashed); n == 0 {
+			return err
+		}
+	}
+
+	delete(s.namespaces, name)
+
+	if err := s.saveNamespaces(); err != nil {
+		s.namespaces[name] = ns
+		return err
+	}
+
+	delete(s.usage, name)
+
+	return nil
+}
+
+// Namespaces returns every namespace sorted by name.
+func (s *Storage) Namespaces() []*Namespace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sortedNamespaces()
+}
+
+func (s *Storage) loadNamespaces() error {
+	r, err := s.backend.Open(namespacesKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var list []*Namespace
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return err
+	}
+
+	for _, ns := range list {
+		s.namespaces[ns.Name] = ns
+	}
+
+	return nil
+}
+
+// ensureNamespaces registers the default namespace and any namespace that
+// indexed files, trashed ones included, refer to but the registry lost.
+func (s *Storage) ensureNamespaces() error {
+	now := time.Now()
+
+	if _, ok := s.namespaces[DefaultNamespace]; !ok {
+		s.namespaces[DefaultNamespace] = &Namespace{Name: DefaultNamespace, CreatedAt: now}
+	}
+
+	for _, index := range []map[string]*FileMeta{s.files, s.trash} {
+		for _, meta := range index {
+			if _, ok := s.namespaces[meta.Namespace]; !ok {
+				s.namespaces[meta.Namespace] = &Namespace{Name: meta.Namespace, CreatedAt: now}
+			}
+		}
+	}
+
+	return s.saveNamespaces()
+}
+
+func (s *Storage) sortedNamespaces() []*Namespace {
+	list := make([]*Namespace, 0, len(s.namespaces))
+	for _, ns := range s.namespaces {
+		list = append(list, ns)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+func (s *Storage) saveNamespaces() error {
+	data, err := json.MarshalIndent(s.sortedNamespaces(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	w, err := s.backend.Create(namespacesKey)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeleteNamespace(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	for _, name := range []string{"empty", "busy"} {
+		if _, err := s.CreateNamespace(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	upload(t, s, "busy", "a.txt", "hello")
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      error
+	}{
+		{"default", DefaultNamespace, ErrNamespaceReserved},
+		{"unknown", "nope", ErrNamespaceNotFound},
+		{"not empty", "busy", ErrNamespaceNotEmpty},
+		{"empty", "empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.DeleteNamespace(tt.namespace); !errors.Is(err, tt.want) {
+				t.Errorf("DeleteNamespace(%s) = %v, want %v", tt.namespace, err, tt.want)
+			}
+		})
+	}
+}
+
+// A file is only reachable through the namespace it was uploaded to.
+func TestNamespaceIsolation(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	if _, err := s.CreateNamespace("team-a", nil); err != nil {
+		t.Fatal(err)
+	}
+	meta := upload(t, s, "team-a", "a.txt", "hello")
+
+	if _, err := s.FindFileByID(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindFileByID from another namespace = %v, want ErrNotFound", err)
+	}
+	if _, _, err := s.Open(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open from another namespace = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete from another namespace = %v, want ErrNotFound", err)
+	}
+	if files, _, err := s.ListFiles(DefaultNamespace, ListOptions{Limit: 10}); err != nil || len(files) != 0 {
+		t.Errorf("ListFiles(default) = %v, %v, want nothing", files, err)
+	}
+
+	if got := readFile(t, s, "team-a", meta.ID); got != "hello" {
+		t.Errorf("content = %q, want %q", got, "hello")
+	}
+	if _, err := s.CreateFile("nope", "a.txt"); !errors.Is(err, ErrNamespaceNotFound) {
+		t.Errorf("CreateFile in an unknown namespace = %v, want ErrNamespaceNotFound", err)
+	}
+}
+
+// A namespace created again under the same name must not inherit the trash
+// of the one that was deleted.
+func TestDeleteNamespacePurgesTrash(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{TrashRetention: time.Hour})
+
+	if _, err := s.CreateNamespace("team-a", nil); err != nil {
+		t.Fatal(err)
+	}
+	meta := upload(t, s, "team-a", "secret.txt", "old tenant")
+
+	if err := s.Delete("team-a", meta.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNamespace("team-a"); err != nil {
+		t.Fatalf("DeleteNamespace: %v", err)
+	}
+
+	if blobExists(t, backend, meta.blob()) {
+		t.Error("blob of the purged file is still stored")
+	}
+
+	// Neither the same process nor a restart may bring the file back.
+	for _, s := range []*Storage{s, newTestStorage(t, backend, Options{TrashRetention: time.Hour})} {
+		if _, err := s.CreateNamespace("team-a", nil); err != nil {
+			t.Fatalf("CreateNamespace again: %v", err)
+		}
+
+		trash, err := s.Trash("team-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trash) != 0 {
+			t.Errorf("new namespace sees %d trashed files of the old one", len(trash))
+		}
+
+		if _, err := s.Restore("team-a", meta.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Restore = %v, want ErrNotFound", err)
+		}
+
+		if err := s.DeleteNamespace("team-a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
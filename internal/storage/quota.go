@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultNamespace holds every file that was not uploaded into a specific
+// namespace.
+const DefaultNamespace = "default"
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota limits what a single namespace may store. Zero means no limit.
+type Quota struct {
+	MaxBytes int64
+	MaxFiles int64
+}
+
+// Usage is what a namespace currently holds. Pending counts uploads that are
+// still being written: their bytes and file slots are reserved up front so
+// that parallel uploads cannot overshoot the quota together.
+type Usage struct {
+	Bytes int64
+	Files int64
+
+	PendingBytes int64
+	PendingFiles int64
+}
+
+type QuotaError struct {
+	Namespace string
+	Resource  string
+	Limit     int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s for namespace '%s': %s limit is %d", ErrQuotaExceeded, e.Namespace, e.Resource, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Usage reports the consumption of a namespace and the quota it is held to.
+func (s *Storage) Usage(namespace string) (Usage, Quota, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return Usage{}, Quota{}, ErrNamespaceNotFound
+	}
+
+	var usage Usage
+	if u := s.usage[namespace]; u != nil {
+		usage = *u
+	}
+
+	return usage, s.quotaOf(namespace), nil
+}
+
+// CheckQuota reports whether size more bytes, and one more file if newFile is
+// set, would still fit into the namespace, without reserving anything.
+func (s *Storage) CheckQuota(namespace string, size int64, newFile bool) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return ErrNamespaceNotFound
+	}
+
+	u := s.usageOf(namespace)
+
+	if newFile {
+		if err := s.checkFiles(namespace, u, 1); err != nil {
+			return err
+		}
+	}
+
+	return s.checkBytes(namespace, u, size)
+}
+
+func (s *Storage) reserveFile(namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return ErrNamespaceNotFound
+	}
+
+	u := s.usageOf(namespace)
+
+	if err := s.checkFiles(namespace, u, 1); err != nil {
+		return err
+	}
+
+	u.PendingFiles++
+	s.usage[namespace] = u
+
+	return nil
+}
+
+func (s *Storage) reserveBytes(namespace string, n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.usageOf(namespace)
+
+	if err := s.checkBytes(namespace, u, n); err != nil {
+		return err
+	}
+
+	u.PendingBytes += n
+	s.usage[namespace] = u
+
+	return nil
+}
+
+// release drops a reservation of files file slots and size bytes. Callers
+// must hold s.mu.
+func (s *Storage) release(namespace string, files, size int64) {
+	u := s.usageOf(namespace)
+	u.PendingFiles -= files
+	u.PendingBytes -= size
+	s.usage[namespace] = u
+}
+
+// account adds (sign 1) or removes (sign -1) a file from usage. Every version
+// kept counts towards the byte quota.
+func (s *Storage) account(meta *FileMeta, sign int64) {
+	u := s.usageOf(meta.Namespace)
+	u.Files += sign
+	u.Bytes += sign * meta.storedSize()
+	s.usage[meta.Namespace] = u
+}
+
+// recount derives usage and blob references from the index.
+func (s *Storage) recount() {
+	s.usage = make(map[string]*Usage)
+	s.refs = make(map[string]*blobRef)
+	s.contents = make(map[contentKey]map[string]*contentRef)
+
+	for _, meta := range s.files {
+		s.account(meta, 1)
+		s.link(meta)
+	}
+	for _, meta := range s.trash {
+		s.link(meta)
+	}
+}
+
+func (s *Storage) usageOf(namespace string) *Usage {
+	if u := s.usage[namespace]; u != nil {
+		return u
+	}
+
+	return &Usage{}
+}
+
+// quotaOf returns the quota the namespace is held to: the one configured
+// for it on the server, else the one it was created with, else the default.
+// Callers must hold s.mu.
+func (s *Storage) quotaOf(namespace string) Quota {
+	if quota, ok := s.quotas[namespace]; ok {
+		return quota
+	}
+
+	if ns := s.namespaces[namespace]; ns != nil && ns.Quota != nil {
+		return *ns.Quota
+	}
+
+	return s.quota
+}
+
+func (s *Storage) checkFiles(namespace string, u *Usage, n int64) error {
+	quota := s.quotaOf(namespace)
+	if quota.MaxFiles > 0 && u.Files+u.PendingFiles+n > quota.MaxFiles {
+		return &QuotaError{Namespace: namespace, Resource: "file count", Limit: quota.MaxFiles}
+	}
+
+	return nil
+}
+
+func (s *Storage) checkBytes(namespace string, u *Usage, n int64) error {
+	quota := s.quotaOf(namespace)
+	if quota.MaxBytes > 0 && u.Bytes+u.PendingBytes+n > quota.MaxBytes {
+		return &QuotaError{Namespace: namespace, Resource: "byte", Limit: quota.MaxBytes}
+	}
+
+	return nil
+}
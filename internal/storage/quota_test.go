@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// startUpload begins an upload of size bytes and leaves it in flight.
+func startUpload(s *Storage, size int) (*Writer, error) {
+	w, err := s.CreateFile(DefaultNamespace, "f.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write([]byte(strings.Repeat("x", size))); err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func usage(t *testing.T, s *Storage, namespace string) Usage {
+	t.Helper()
+
+	u, _, err := s.Usage(namespace)
+	if err != nil {
+		t.Fatalf("Usage(%s): %v", namespace, err)
+	}
+
+	return u
+}
+
+func TestQuotaReservation(t *testing.T) {
+	tests := []struct {
+		name  string
+		quota Quota
+		// held are the sizes of the uploads in flight when next starts.
+		held []int
+		next int
+		want error
+	}{
+		{"no quota", Quota{}, []int{100, 100}, 100, nil},
+		{"files free", Quota{MaxFiles: 2}, []int{1}, 1, nil},
+		{"files reserved", Quota{MaxFiles: 2}, []int{1, 1}, 1, ErrQuotaExceeded},
+		{"bytes free", Quota{MaxBytes: 10}, []int{4}, 6, nil},
+		{"bytes reserved", Quota{MaxBytes: 10}, []int{4, 4}, 3, ErrQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t, NewMemory(), Options{Quota: tt.quota})
+
+			var held []*Writer
+			for _, size := range tt.held {
+				w, err := startUpload(s, size)
+				if err != nil {
+					t.Fatalf("held upload: %v", err)
+				}
+				held = append(held, w)
+			}
+
+			w, err := startUpload(s, tt.next)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("upload = %v, want %v", err, tt.want)
+			}
+			if err == nil {
+				held = append(held, w)
+			}
+
+			for _, w := range held {
+				if err := w.Abort(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if u := usage(t, s, DefaultNamespace); u != (Usage{}) {
+				t.Errorf("usage after aborting everything = %+v, want none", u)
+			}
+
+			// The released reservations make room again.
+			w, err = startUpload(s, tt.next)
+			if err != nil {
+				t.Fatalf("upload after release: %v", err)
+			}
+			w.Abort()
+		})
+	}
+}
+
+func TestQuotaCommitAndDelete(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{Quota: Quota{MaxBytes: 10, MaxFiles: 1}})
+
+	meta := upload(t, s, DefaultNamespace, "a.txt", "hello")
+
+	if u := usage(t, s, DefaultNamespace); u != (Usage{Bytes: 5, Files: 1}) {
+		t.Errorf("usage after commit = %+v, want 5 bytes in 1 file", u)
+	}
+
+	if _, err := startUpload(s, 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("upload over the file quota = %v, want ErrQuotaExceeded", err)
+	}
+
+	if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if u := usage(t, s, DefaultNamespace); u != (Usage{}) {
+		t.Errorf("usage after delete = %+v, want none", u)
+	}
+}
+
+func TestNamespaceQuotas(t *testing.T) {
+	backend := NewMemory()
+	opts := Options{
+		Quota: Quota{MaxBytes: 10, MaxFiles: 1},
+		NamespaceQuotas: map[string]Quota{
+			"big":       {MaxBytes: 100, MaxFiles: 3},
+			"unlimited": {},
+			// The server setting wins over the quota given on creation.
+			"pinned": {MaxBytes: 20, MaxFiles: 2},
+		},
+	}
+	s := newTestStorage(t, backend, opts)
+
+	created := map[string]*Quota{
+		"big":       nil,
+		"unlimited": nil,
+		"pinned":    {MaxBytes: 1000, MaxFiles: 1000},
+		"own":       {MaxBytes: 50, MaxFiles: 2},
+		"other":     nil,
+	}
+	for name, quota := range created {
+		if _, err := s.CreateNamespace(name, quota); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		namespace string
+		quota     Quota
+		// fits and exceeds are sizes of a next file that does and does not
+		// fit into the namespace.
+		fits, exceeds int64
+	}{
+		{"big", Quota{MaxBytes: 100, MaxFiles: 3}, 100, 101},
+		{"unlimited", Quota{}, 1 << 40, -1},
+		{"pinned", Quota{MaxBytes: 20, MaxFiles: 2}, 20, 21},
+		{"own", Quota{MaxBytes: 50, MaxFiles: 2}, 50, 51},
+		{"other", Quota{MaxBytes: 10, MaxFiles: 1}, 10, 11},
+		{DefaultNamespace, Quota{MaxBytes: 10, MaxFiles: 1}, 10, 11},
+	}
+
+	// The quotas created with the namespaces outlive a restart.
+	for _, s := range []*Storage{s, newTestStorage(t, backend, opts)} {
+		for _, tt := range tests {
+			t.Run(tt.namespace, func(t *testing.T) {
+				if _, quota, _ := s.Usage(tt.namespace); quota != tt.quota {
+					t.Errorf("quota = %+v, want %+v", quota, tt.quota)
+				}
+
+				if err := s.CheckQuota(tt.namespace, tt.fits, true); err != nil {
+					t.Errorf("CheckQuota(%d) = %v, want nil", tt.fits, err)
+				}
+				if tt.exceeds < 0 {
+					return
+				}
+
+				err := s.CheckQuota(tt.namespace, tt.exceeds, true)
+				var quotaErr *QuotaError
+				if !errors.As(err, &quotaErr) || quotaErr.Namespace != tt.namespace || quotaErr.Limit != tt.quota.MaxBytes {
+					t.Errorf("CheckQuota(%d) = %v, want the %s byte limit", tt.exceeds, err, tt.namespace)
+				}
+			})
+		}
+	}
+
+	// The file count is held per namespace as well.
+	for _, tt := range tests {
+		if tt.quota.MaxFiles == 0 {
+			continue
+		}
+
+		for i := range tt.quota.MaxFiles {
+			upload(t, s, tt.namespace, fmt.Sprintf("%d.txt", i), "x")
+		}
+		if _, err := s.CreateFile(tt.namespace, "one-too-many.txt"); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("CreateFile(%s) over %d files = %v, want ErrQuotaExceeded", tt.namespace, tt.quota.MaxFiles, err)
+		}
+	}
+}
+
+func TestCreateNamespaceInvalidQuota(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+
+	for _, quota := range []Quota{{MaxBytes: -1}, {MaxFiles: -1}} {
+		if _, err := s.CreateNamespace("team-a", &quota); !errors.Is(err, ErrInvalidQuota) {
+			t.Errorf("CreateNamespace with %+v = %v, want ErrInvalidQuota", quota, err)
+		}
+	}
+}
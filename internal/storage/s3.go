@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	// s3PartSize is how much of an upload is buffered before it is sent as
+	// one multipart part. S3 accepts parts from 5 MiB but at most 10000 of
+	// them, so 16 MiB parts keep uploads of unknown size working up to
+	// ~156 GiB at the cost of 16 MiB of memory per upload.
+	s3PartSize = 16 * 1024 * 1024
+)
+
+var errUploadAborted = errors.New("upload aborted")
+
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	Region    string
+	UseSSL    bool
+}
+
+type S3Backend struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3(cfg S3Config) (*S3Backend, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Backend{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+// Create streams the blob to S3 as it is written. The object size is not
+// known in advance, so the client switches to a multipart upload and only
+// completes it on Close; Abort makes it abort the multipart upload instead.
+func (b *S3Backend) Create(key string) (BlobWriter, error) {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := b.client.PutObject(context.Background(), b.bucket, b.object(key), pr, -1,
+			minio.PutObjectOptions{PartSize: s3PartSize})
+		pr.CloseWithError(err)
+		done <- err
+	}()
+
+	return &s3Writer{pipe: pw, done: done}, nil
+}
+
+func (b *S3Backend) Open(key string) (io.ReadSeekCloser, error) {
+	obj, err := b.client.GetObject(context.Background(), b.bucket, b.object(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, s3Error(err)
+	}
+
+	return obj, nil
+}
+
+func (b *S3Backend) Stat(key string) (BlobInfo, error) {
+	info, err := b.client.StatObject(context.Background(), b.bucket, b.object(key), minio.StatObjectOptions{})
+	if err != nil {
+		return BlobInfo{}, s3Error(err)
+	}
+
+	return BlobInfo{Key: key, Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+func (b *S3Backend) List(prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+
+	objects := b.client.ListObjects(context.Background(), b.bucket, minio.ListObjectsOptions{
+		Prefix:    b.object(prefix),
+		Recursive: true,
+	})
+
+	for obj := range objects {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+
+		key := obj.Key[len(b.object("")):]
+		blobs = append(blobs, BlobInfo{Key: key, Size: obj.Size, ModTime: obj.LastModified})
+	}
+
+	return blobs, nil
+}
+
+func (b *S3Backend) Delete(key string) error {
+	err := b.client.RemoveObject(context.Background(), b.bucket, b.object(key), minio.RemoveObjectOptions{})
+
+	return s3Error(err)
+}
+
+// Rename copies the object on the server side and deletes the original, S3
+// having no rename of its own.
+func (b *S3Backend) Rename(from, to string) error {
+	_, err := b.client.CopyObject(context.Background(),
+		minio.CopyDestOptions{Bucket: b.bucket, Object: b.object(to)},
+		minio.CopySrcOptions{Bucket: b.bucket, Object: b.object(from)})
+	if err != nil {
+		return s3Error(err)
+	}
+
+	return b.Delete(from)
+}
+
+func (b *S3Backend) object(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+
+	return b.prefix + "/" + key
+}
+
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+type s3Writer struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pipe.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	w.pipe.Close()
+
+	return <-w.done
+}
+
+func (w *s3Writer) Abort() error {
+	w.pipe.CloseWithError(errUploadAborted)
+
+	if err := <-w.done; err != nil && !errors.Is(err, errUploadAborted) {
+		return err
+	}
+
+	return nil
+}
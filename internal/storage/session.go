@@ -0,0 +1,426 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	sessionMetaExt = ".json"
+	sessionDataExt = ".part"
+)
+
+var (
+	ErrSessionBusy      = errors.New("upload session is in use")
+	ErrSessionCommitted = errors.New("upload session is already committed")
+)
+
+type OffsetMismatchError struct {
+	Expected int64
+	Got      int64
+}
+
+func (e *OffsetMismatchError) Error() string {
+	return fmt.Sprintf("offset %d does not match committed offset %d", e.Got, e.Expected)
+}
+
+type Session struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Target is the file the session uploads a new version of, if any.
+	Target    string    `json:"target,omitempty"`
+	FileID    string    `json:"file_id,omitempty"`
+	Version   int64     `json:"version,omitempty"`
+	Checksum  string    `json:"checksum,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// FileExpiresAt is the expiry the file gets once committed.
+	FileExpiresAt time.Time `json:"file_expires_at,omitzero"`
+
+	// Offset is the number of bytes durably staged so far. It is not
+	// persisted: the staged file size is the source of truth.
+	Offset int64 `json:"-"`
+
+	busy bool
+}
+
+// Sessions keeps resumable uploads in a local staging directory. Data is
+// appended to "<id>.part" and only handed to Storage once the client marks
+// the last chunk as final, so the backend never sees partial files.
+type Sessions struct {
+	dir     string
+	storage *Storage
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+
+	// dropped lists the sessions load found without their staged data.
+	dropped []string
+}
+
+func NewSessions(dir string, storage *Storage, ttl time.Duration) (*Sessions, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &Sessions{
+		dir:      dir,
+		storage:  storage,
+		ttl:      ttl,
+		sessions: make(map[string]*Session),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Dropped returns the ids of the sessions that were discarded on start
+// because their staged data was missing.
+func (s *Sessions) Dropped() []string {
+	return s.dropped
+}
+
+// Create starts a session for a new file, or for a new version of target if
+// it is not empty. A non-zero expiresAt is applied to the file on commit.
+func (s *Sessions) Create(namespace, fileName, target string, expiresAt time.Time) (*Session, error) {
+	now := time.Now()
+	session := &Session{
+		ID:            uuid.NewString(),
+		Namespace:     namespace,
+		Name:          fileName,
+		Target:        target,
+		FileExpiresAt: expiresAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	file, err := os.Create(s.dataPath(session.ID))
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	if err := s.save(session); err != nil {
+		os.Remove(s.dataPath(session.ID))
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.sessions[session.ID] = session
+	s.mu.Unlock()
+
+	return session.snapshot(), nil
+}
+
+func (s *Sessions) Get(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return session.snapshot(), nil
+}
+
+func (s *Sessions) ExpiresAt(session *Session) time.Time {
+	return session.UpdatedAt.Add(s.ttl)
+}
+
+// Open locks the session for one writing stream and returns a writer that
+// appends at the committed offset.
+func (s *Sessions) Open(id string) (*SessionWriter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if session.FileID != "" {
+		return nil, ErrSessionCommitted
+	}
+	if session.busy {
+		return nil, ErrSessionBusy
+	}
+
+	file, err := os.OpenFile(s.dataPath(id), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	session.busy = true
+
+	return &SessionWriter{sessions: s, session: session, file: file}, nil
+}
+
+// Expire removes sessions that have not been written to within the TTL,
+// committed ones included, and returns how many were removed.
+func (s *Sessions) Expire(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+
+	for id, session := range s.sessions {
+		if session.busy || now.Before(s.ExpiresAt(session)) {
+			continue
+		}
+
+		if err := s.remove(id); err != nil {
+			return removed, err
+		}
+		delete(s.sessions, id)
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (s *Sessions) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), sessionMetaExt)
+		if !ok {
+			continue
+		}
+
+		data, err := os.ReadFile(s.metaPath(id))
+		if err != nil {
+			return err
+		}
+
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			return err
+		}
+
+		if session.Namespace == "" {
+			session.Namespace = DefaultNamespace
+		}
+
+		if session.FileID == "" {
+			info, err := os.Stat(s.dataPath(id))
+			if errors.Is(err, os.ErrNotExist) {
+				// Without the staged data the upload cannot be resumed,
+				// so the client has to start over.
+				if err := s.remove(id); err != nil {
+					return err
+				}
+				s.dropped = append(s.dropped, id)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			session.Offset = info.Size()
+		}
+
+		s.sessions[id] = &session
+	}
+
+	// Staged data left behind by a failed remove.
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), sessionDataExt)
+		if !ok || s.sessions[id] != nil {
+			continue
+		}
+
+		if err := os.Remove(s.dataPath(id)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Sessions) save(session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.metaPath(session.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.metaPath(session.ID))
+}
+
+// remove deletes the meta file first, so a failure in between leaves only
+// staged data that load cleans up.
+func (s *Sessions) remove(id string) error {
+	if err := os.Remove(s.metaPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err := os.Remove(s.dataPath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Sessions) metaPath(id string) string {
+	return filepath.Join(s.dir, id+sessionMetaExt)
+}
+
+func (s *Sessions) dataPath(id string) string {
+	return filepath.Join(s.dir, id+sessionDataExt)
+}
+
+func (s *Session) snapshot() *Session {
+	c := *s
+	return &c
+}
+
+type SessionWriter struct {
+	sessions *Sessions
+	session  *Session
+	file     *os.File
+	aborted  bool
+}
+
+func (w *SessionWriter) Session() *Session {
+	w.sessions.mu.Lock()
+	defer w.sessions.mu.Unlock()
+
+	return w.session.snapshot()
+}
+
+// WriteAt appends data that the client claims starts at offset. Anything
+// other than the committed offset is rejected, so a resumed stream can
+// neither leave a gap nor write the same bytes twice.
+func (w *SessionWriter) WriteAt(data []byte, offset int64) error {
+	w.sessions.mu.Lock()
+	expected := w.session.Offset
+	w.sessions.mu.Unlock()
+
+	if offset != expected {
+		return &OffsetMismatchError{Expected: expected, Got: offset}
+	}
+
+	n, err := w.file.Write(data)
+
+	w.sessions.mu.Lock()
+	w.session.Offset += int64(n)
+	w.session.UpdatedAt = time.Now()
+	w.sessions.mu.Unlock()
+
+	return err
+}
+
+// Commit moves the staged data into Storage and records the resulting file
+// id on the session, so a client that lost the response can still find it.
+// If the content does not match expected, nothing is stored.
+func (w *SessionWriter) Commit(expected string) (*FileMeta, error) {
+	if err := w.file.Sync(); err != nil {
+		return nil, err
+	}
+
+	staged, err := os.Open(w.file.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer staged.Close()
+
+	var file *Writer
+	if w.session.Target != "" {
+		file, err = w.sessions.storage.UpdateFile(w.session.Namespace, w.session.Target, w.session.Name)
+	} else {
+		file, err = w.sessions.storage.CreateFile(w.session.Namespace, w.session.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.ExpireAt(w.session.FileExpiresAt)
+
+	if _, err := io.Copy(file, staged); err != nil {
+		file.Abort()
+		return nil, err
+	}
+
+	if err := file.Verify(expected); err != nil {
+		file.Abort()
+		return nil, err
+	}
+
+	if err := file.Commit(); err != nil {
+		return nil, err
+	}
+
+	w.sessions.mu.Lock()
+	w.session.FileID = file.ID()
+	w.session.Version = file.Version()
+	w.session.Checksum = file.Checksum()
+	w.session.UpdatedAt = time.Now()
+	session := w.session.snapshot()
+	w.sessions.mu.Unlock()
+
+	if err := w.sessions.save(session); err != nil {
+		return nil, err
+	}
+
+	os.Remove(w.file.Name())
+
+	return file.meta, nil
+}
+
+// Abort drops the session together with everything staged so far.
+func (w *SessionWriter) Abort() error {
+	w.file.Close()
+	w.aborted = true
+
+	w.sessions.mu.Lock()
+	defer w.sessions.mu.Unlock()
+
+	delete(w.sessions.sessions, w.session.ID)
+
+	return w.sessions.remove(w.session.ID)
+}
+
+// Close flushes the staged data and releases the session for the next
+// stream. It is safe to call after Commit.
+func (w *SessionWriter) Close() error {
+	if w.aborted {
+		return nil
+	}
+
+	err := w.file.Sync()
+	w.file.Close()
+
+	w.sessions.mu.Lock()
+	w.session.busy = false
+	session := w.session.snapshot()
+	w.sessions.mu.Unlock()
+
+	if session.FileID == "" {
+		if saveErr := w.sessions.save(session); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+
+	return err
+}
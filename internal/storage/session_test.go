@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestSessions(t *testing.T, dir string, s *Storage) *Sessions {
+	t.Helper()
+
+	sessions, err := NewSessions(dir, s, time.Hour)
+	if err != nil {
+		t.Fatalf("NewSessions: %v", err)
+	}
+
+	return sessions
+}
+
+func writeSession(t *testing.T, sessions *Sessions, id string, data string, offset int64) error {
+	t.Helper()
+
+	w, err := sessions.Open(id)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", id, err)
+	}
+	defer w.Close()
+
+	return w.WriteAt([]byte(data), offset)
+}
+
+func TestSessionResume(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStorage(t, NewMemory(), Options{})
+	sessions := newTestSessions(t, dir, s)
+
+	session, err := sessions.Create(DefaultNamespace, "a.txt", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSession(t, sessions, session.ID, "hel", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted server picks the session up at the staged offset.
+	sessions = newTestSessions(t, dir, s)
+	got, err := sessions.Get(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Offset != 3 {
+		t.Fatalf("Offset after restart = %d, want 3", got.Offset)
+	}
+
+	var mismatch *OffsetMismatchError
+	if err := writeSession(t, sessions, session.ID, "lo", 0); !errors.As(err, &mismatch) || mismatch.Expected != 3 {
+		t.Errorf("WriteAt at a stale offset = %v, want an OffsetMismatchError at 3", err)
+	}
+
+	w, err := sessions.Open(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.Open(session.ID); !errors.Is(err, ErrSessionBusy) {
+		t.Errorf("second Open = %v, want ErrSessionBusy", err)
+	}
+	if err := w.WriteAt([]byte("lo"), 3); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := w.Commit("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	if got := readFile(t, s, DefaultNamespace, meta.ID); got != "hello" {
+		t.Errorf("committed content = %q, want %q", got, "hello")
+	}
+
+	// The file id outlives a restart, for a client that lost the response.
+	sessions = newTestSessions(t, dir, s)
+	got, err = sessions.Get(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FileID != meta.ID {
+		t.Errorf("FileID after restart = %q, want %q", got.FileID, meta.ID)
+	}
+	if _, err := sessions.Open(session.ID); !errors.Is(err, ErrSessionCommitted) {
+		t.Errorf("Open of a committed session = %v, want ErrSessionCommitted", err)
+	}
+}
+
+func TestSessionExpire(t *testing.T) {
+	dir := t.TempDir()
+	sessions := newTestSessions(t, dir, newTestStorage(t, NewMemory(), Options{}))
+
+	stale, err := sessions.Create(DefaultNamespace, "stale.txt", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy, err := sessions.Create(DefaultNamespace, "busy.txt", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := sessions.Open(busy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	n, err := sessions.Expire(time.Now())
+	if err != nil || n != 0 {
+		t.Fatalf("Expire before the TTL = %d, %v, want 0", n, err)
+	}
+
+	n, err = sessions.Expire(time.Now().Add(2 * time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Expire after the TTL = %d, %v, want 1", n, err)
+	}
+	if _, err := sessions.Get(stale.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of expired session = %v, want ErrNotFound", err)
+	}
+	if _, err := sessions.Get(busy.ID); err != nil {
+		t.Errorf("Get of busy session = %v", err)
+	}
+
+	for _, path := range []string{sessions.metaPath(stale.ID), sessions.dataPath(stale.ID)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Stat(%s) = %v, want not exist", path, err)
+		}
+	}
+}
+
+func TestSessionMissingData(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStorage(t, NewMemory(), Options{})
+	sessions := newTestSessions(t, dir, s)
+
+	lost, err := sessions.Create(DefaultNamespace, "lost.txt", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := sessions.Create(DefaultNamespace, "kept.txt", "", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(sessions.dataPath(lost.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A leftover of a remove that failed after the meta file was deleted.
+	orphan := sessions.dataPath("orphan")
+	if err := os.WriteFile(orphan, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions = newTestSessions(t, dir, s)
+
+	if got := sessions.Dropped(); len(got) != 1 || got[0] != lost.ID {
+		t.Errorf("Dropped = %v, want [%s]", got, lost.ID)
+	}
+	if _, err := sessions.Get(lost.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of session without data = %v, want ErrNotFound", err)
+	}
+	if _, err := sessions.Get(kept.ID); err != nil {
+		t.Errorf("Get of intact session = %v", err)
+	}
+	for _, path := range []string{sessions.metaPath(lost.ID), orphan} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Stat(%s) = %v, want not exist", path, err)
+		}
+	}
+}
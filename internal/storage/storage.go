@@ -1,9 +1,20 @@
 package storage
 
 import (
-	"os"
-	"path/filepath"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	pb "github.com/YotoHana/tages-test-case/api/proto"
 	"github.com/google/uuid"
@@ -11,76 +22,761 @@ import (
 )
 
 const (
-	storageRoot = "./uploads"
+	blobsPrefix = "blobs/"
+	indexKey    = "index.json"
+
+	// incomingPrefix holds uploads whose content hash is not known yet.
+	// Committing moves them under blobs/, named by that hash.
+	incomingPrefix = "incoming/"
+
+	// sniffLen is how many leading bytes http.DetectContentType looks at.
+	sniffLen = 512
+)
+
+var (
+	ErrNotFound         = errors.New("file not found")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
 )
 
-type Storage struct{}
+// FileMeta describes a file and its current version. CreatedAt is when the
+// file was first uploaded, UpdatedAt when its current version was.
+type FileMeta struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Version   int64  `json:"version"`
+	// Blob names the blob holding the current version: the hex SHA-256 of
+	// its content, so identical content is stored once. Files indexed before
+	// versioning have it empty and keep their content under their ID.
+	Blob        string    `json:"blob,omitempty"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// ExpiresAt is when the file is deleted for good. Zero means never.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// DeletedAt is set while the file is in the trash.
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+
+	// History holds the previous versions, newest first.
+	History []*Version `json:"history,omitempty"`
+}
+
+func (m *FileMeta) Info() *pb.FileInfo {
+	return &pb.FileInfo{
+		Id:          m.ID,
+		Name:        m.Name,
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Checksum:    m.Checksum,
+		CreatedAt:   timestamppb.New(m.CreatedAt),
+		UpdatedAt:   timestamppb.New(m.UpdatedAt),
+		Namespace:   m.Namespace,
+		Version:     m.Version,
+		ExpiresAt:   timestamp(m.ExpiresAt),
+	}
+}
+
+func (m *FileMeta) Item() *pb.ListResponse_Item {
+	return &pb.ListResponse_Item{
+		Id:        m.ID,
+		Name:      m.Name,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+		UpdatedAt: timestamppb.New(m.UpdatedAt),
+		Size:      m.Size,
+		Version:   m.Version,
+		ExpiresAt: timestamp(m.ExpiresAt),
+	}
+}
+
+// timestamp converts t, leaving the zero time unset rather than 1970.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+type Storage struct {
+	backend Backend
+
+	mu sync.RWMutex
+	// files is the id -> metadata index. It is loaded once on startup and
+	// kept in sync on every commit, so lookups never touch the backend.
+	files      map[string]*FileMeta
+	namespaces map[string]*Namespace
+	// trash holds deleted files until they are purged. They are kept out of
+	// files so that nothing but Trash and Restore can see them.
+	trash map[string]*FileMeta
+	// seq is the last journal record written. Guarded by mu.
+	seq uint64
+
+	// compactMu keeps one Compact at a time, so an older snapshot never
+	// overwrites a newer one. compacted is the last journal record that
+	// index.json covers, pruned the last one deleted since. Records are
+	// numbered without gaps, so those in between are all there is.
+	compactMu sync.Mutex
+	compacted uint64
+	pruned    uint64
+
+	// quota applies to every namespace that has neither an entry in quotas
+	// nor a quota of its own.
+	quota          Quota
+	quotas         map[string]Quota
+	maxVersions    int
+	trashRetention time.Duration
+	// usage, refs and contents are derived from the index on startup and
+	// then updated on every commit and delete instead of being recomputed.
+	usage    map[string]*Usage
+	refs     map[string]*blobRef
+	contents map[contentKey]map[string]*contentRef
+}
+
+type Options struct {
+	// Quota is the default quota of a namespace.
+	Quota Quota
+	// NamespaceQuotas overrides Quota, and the quota a namespace was
+	// created with, for single namespaces.
+	NamespaceQuotas map[string]Quota
+	// MaxVersions is how many previous versions are kept per file; older
+	// ones are deleted when a new version is committed. 0 keeps all.
+	MaxVersions int
+	// TrashRetention is how long deleted files can be restored before
+	// PurgeTrash removes them. 0 deletes files right away.
+	TrashRetention time.Duration
+}
 
-func New() (*Storage, error) {
-	if err := os.MkdirAll(storageRoot, 0755); err != nil {
+func New(backend Backend, opts Options) (*Storage, error) {
+	s := &Storage{
+		backend:        backend,
+		files:          make(map[string]*FileMeta),
+		namespaces:     make(map[string]*Namespace),
+		trash:          make(map[string]*FileMeta),
+		quota:          opts.Quota,
+		quotas:         opts.NamespaceQuotas,
+		maxVersions:    opts.MaxVersions,
+		trashRetention: opts.TrashRetention,
+	}
+
+	if err := s.load(); err != nil {
 		return nil, err
 	}
-	return &Storage{}, nil
+
+	if err := s.loadNamespaces(); err != nil {
+		return nil, err
+	}
+
+	if err := s.rebuild(); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNamespaces(); err != nil {
+		return nil, err
+	}
+
+	s.recount()
+
+	return s, nil
 }
 
-func (s *Storage) CreateFile(fileName string) (file *os.File, id string, err error) {
-	id = uuid.NewString()
-	resultName := strings.Join([]string{id, fileName}, "_")
-	file, err = os.Create(filepath.Join(storageRoot, resultName))
+func (s *Storage) CreateFile(namespace, fileName string) (*Writer, error) {
+	id := uuid.NewString()
+
+	if err := s.reserveFile(namespace); err != nil {
+		return nil, err
+	}
+
+	incoming := incomingKey()
+
+	blob, err := s.backend.Create(incoming)
 	if err != nil {
-		return nil, "", err
+		s.mu.Lock()
+		s.release(namespace, 1, 0)
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	w := &Writer{
+		storage:  s,
+		blob:     blob,
+		incoming: incoming,
+		hash:     sha256.New(),
+		meta:     &FileMeta{ID: id, Namespace: namespace, Name: fileName},
+	}
+
+	return w, nil
+}
+
+// FindFileByID looks the file up within namespace only: a file from another
+// namespace is reported as not found rather than as forbidden, so ids cannot
+// be probed across namespaces.
+func (s *Storage) FindFileByID(namespace, id string) (*FileMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	meta, ok := s.files[id]
+	if !ok || meta.Namespace != namespace {
+		return nil, ErrNotFound
+	}
+
+	return meta, nil
+}
+
+func (s *Storage) Open(namespace, id string) (io.ReadSeekCloser, *FileMeta, error) {
+	meta, err := s.FindFileByID(namespace, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.backend.Open(blobKey(meta.blob()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blob, meta, nil
+}
+
+// Delete moves a file with all its versions to the trash, or removes it for
+// good if the trash is disabled.
+func (s *Storage) Delete(namespace, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, ok := s.files[id]
+	if !ok || meta.Namespace != namespace {
+		return ErrNotFound
+	}
+
+	if s.trashRetention > 0 {
+		return s.moveToTrash(meta)
+	}
+
+	delete(s.files, id)
+	s.account(meta, -1)
+
+	if err := s.save(id); err != nil {
+		s.files[id] = meta
+		s.account(meta, 1)
+		return err
+	}
+
+	return s.deleteBlobs(s.unlink(meta))
+}
+
+// deleteBlobs removes blobs that are no longer referenced. Missing blobs are
+// not an error: the goal is only that they are gone.
+func (s *Storage) deleteBlobs(names []string) error {
+	var errs []error
+
+	for _, name := range names {
+		err := s.backend.Delete(blobKey(name))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sorted lists every indexed file, trashed ones included.
+func (s *Storage) sorted() []*FileMeta {
+	list := make([]*FileMeta, 0, len(s.files)+len(s.trash))
+	for _, meta := range s.files {
+		list = append(list, meta)
+	}
+	for _, meta := range s.trash {
+		list = append(list, meta)
 	}
 
-	return file, id, nil
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
 }
 
-func (s *Storage) GetFileList() (items []*pb.ListResponse_Item, err error) {
-	entries, err := os.ReadDir(storageRoot)
+// add stores the content of a committed upload under its hash and indexes
+// it, either as a new file or as the next version of an existing one. If the
+// same content is already stored, the upload is dropped and the blob shared.
+// The reservation turns into usage in the same step, so the quota never
+// sees the file counted twice or not at all.
+func (s *Storage) add(w *Writer) (*FileMeta, error) {
+	name := w.meta.Blob
+
+	s.mu.Lock()
+	stored := s.pin(name, w.meta.Size)
+	s.mu.Unlock()
+
+	// The blob is moved without the lock held: the pin keeps a concurrent
+	// delete from removing it meanwhile. Two uploads of the same content
+	// may both move it; they write identical bytes.
+	var err error
+	if stored {
+		s.backend.Delete(w.incoming)
+	} else if err = s.backend.Rename(w.incoming, blobKey(name)); err != nil {
+		s.backend.Delete(w.incoming)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer w.release()
+
+	var meta *FileMeta
+	var unused []string
+
+	if err == nil {
+		meta, unused, err = s.index(w)
+	}
+
+	s.deleteBlobs(append(unused, s.unpin(name)...))
+
+	return meta, err
+}
+
+// index adds the upload to the index. Callers must hold s.mu.
+func (s *Storage) index(w *Writer) (*FileMeta, []string, error) {
+	meta := w.meta
+
+	if w.update {
+		current, ok := s.files[meta.ID]
+		if !ok || current.Namespace != meta.Namespace {
+			return nil, nil, ErrNotFound
+		}
+
+		meta = current.withVersion(w.meta.current(), w.meta.Name, s.maxVersions)
+		if !w.meta.ExpiresAt.IsZero() {
+			meta.ExpiresAt = w.meta.ExpiresAt
+		}
+	}
+
+	unused, err := s.replace(meta)
 	if err != nil {
+		return nil, nil, err
+	}
+
+	return meta, unused, nil
+}
+
+// replace swaps the index entry for meta.ID, moving usage and blob
+// references along with it, and persists the index. It returns the blobs
+// only the old entry referred to. Index entries are never modified in place
+// because readers hold on to them without the lock. Callers must hold s.mu.
+func (s *Storage) replace(meta *FileMeta) ([]string, error) {
+	old, existed := s.files[meta.ID]
+
+	s.files[meta.ID] = meta
+	if existed {
+		s.account(old, -1)
+	}
+	s.account(meta, 1)
+
+	if err := s.save(meta.ID); err != nil {
+		s.account(meta, -1)
+		if existed {
+			s.files[meta.ID] = old
+			s.account(old, 1)
+		} else {
+			delete(s.files, meta.ID)
+		}
 		return nil, err
 	}
 
-	items = make([]*pb.ListResponse_Item, 0, len(entries))
+	s.link(meta)
+	if existed {
+		return s.unlink(old), nil
+	}
+
+	return nil, nil
+}
+
+func (s *Storage) load() error {
+	r, err := s.backend.Open(indexKey)
+	if errors.Is(err, ErrNotFound) {
+		return s.replay()
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var list []*FileMeta
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return err
+	}
+
+	for _, meta := range list {
+		s.put(meta)
+	}
+
+	return s.replay()
+}
+
+// put indexes an entry read from index.json or the journal, filling in what
+// older entries lack, in the trash if it was deleted.
+func (s *Storage) put(meta *FileMeta) {
+	if meta.Namespace == "" {
+		meta.Namespace = DefaultNamespace
+	}
+	if meta.Version == 0 {
+		meta.Version = 1
+	}
+
+	delete(s.files, meta.ID)
+	delete(s.trash, meta.ID)
+
+	if !meta.DeletedAt.IsZero() {
+		s.trash[meta.ID] = meta
+		return
+	}
+	s.files[meta.ID] = meta
+}
+
+// rebuild reconciles the index with what the backend actually holds: entries
+// whose current blob is gone are dropped, versions whose blob is gone are
+// forgotten, per-file blobs of a lost entry are indexed again, content blobs
+// nothing refers to are indexed in LostFoundNamespace, uploads that never
+// got committed are removed, and files left in the old "<uuid>_<name>"
+// layout are moved under blobs/.
+func (s *Storage) rebuild() error {
+	incoming, err := s.backend.List(incomingPrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range incoming {
+		if err := s.backend.Delete(e.Key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	entries, err := s.backend.List("")
+	if err != nil {
+		return err
+	}
 
 	for _, e := range entries {
-		fileInfo, err := e.Info()
+		if strings.Contains(e.Key, "/") {
+			continue
+		}
+
+		id, name, ok := strings.Cut(e.Key, "_")
+		if !ok || uuid.Validate(id) != nil {
+			continue
+		}
+
+		if err := s.move(e.Key, blobKey(id)); err != nil {
+			return err
+		}
+
+		v, err := s.scanBlob(id)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		// The blob was copied, so only the original tells the file's age.
+		v.CreatedAt = e.ModTime
+		meta := newFileMeta(id, v)
+		meta.Name = name
+		s.files[id] = meta
+	}
+
+	blobs, err := s.backend.List(blobsPrefix)
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool)
+	for _, index := range []map[string]*FileMeta{s.files, s.trash} {
+		for _, meta := range index {
+			for _, name := range meta.blobs() {
+				referenced[name] = true
+			}
+		}
+	}
 
-		fileName := strings.Split(e.Name(), "_")
+	onDisk := make(map[string]bool, len(blobs))
+	unknown := make(map[string][]BlobInfo)
 
-		ts := timestamppb.New(fileInfo.ModTime())
+	for _, e := range blobs {
+		name := strings.TrimPrefix(e.Key, blobsPrefix)
+		onDisk[name] = true
 
-		item := &pb.ListResponse_Item{
-			Id: fileName[0],
-			Name: fileName[1],
-			CreatedAt: ts,
-			UpdatedAt: ts,
+		if referenced[name] {
+			continue
 		}
 
-		items = append(items, item)
+		// Content blobs do not tell which file they belonged to, so each
+		// one is quarantined as a file of its own.
+		if isContentBlob(name) {
+			v, err := s.scanBlob(name)
+			if err != nil {
+				return err
+			}
+
+			meta := newFileMeta(uuid.NewString(), v)
+			meta.Namespace = LostFoundNamespace
+			s.files[meta.ID] = meta
+			continue
+		}
+
+		id, _, _ := strings.Cut(name, ".")
+		unknown[id] = append(unknown[id], BlobInfo{Key: name, Size: e.Size, ModTime: e.ModTime})
 	}
 
-	return items, nil
+	for id, found := range unknown {
+		// A version blob of a known file that the index does not mention
+		// was written by an update that never got committed.
+		if s.files[id] != nil || s.trash[id] != nil {
+			for _, b := range found {
+				if err := s.backend.Delete(blobKey(b.Key)); err != nil && !errors.Is(err, ErrNotFound) {
+					return err
+				}
+				delete(onDisk, b.Key)
+			}
+			continue
+		}
+
+		// Otherwise the index entry was lost: the blobs are the versions of
+		// one file, the most recently written being the current one.
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].ModTime.Before(found[j].ModTime)
+		})
+
+		var meta *FileMeta
+		for _, b := range found {
+			v, err := s.scanBlob(b.Key)
+			if err != nil {
+				return err
+			}
+
+			if meta == nil {
+				meta = newFileMeta(id, v)
+			} else {
+				meta = meta.withVersion(v, meta.Name, 0)
+			}
+		}
+		s.files[id] = meta
+	}
+
+	for _, index := range []map[string]*FileMeta{s.files, s.trash} {
+		for id, meta := range index {
+			if !onDisk[meta.blob()] {
+				delete(index, id)
+				continue
+			}
+
+			history := meta.History[:0:0]
+			for _, v := range meta.History {
+				if onDisk[v.Blob] {
+					history = append(history, v)
+				}
+			}
+			meta.History = history
+		}
+	}
+
+	// Nothing else uses the store before New returns.
+	return s.compact(s.sorted(), s.seq)
 }
 
-func (s *Storage) FindFileByID(id string) (fullPath string, originalName string, err error) {
-	entries, err := os.ReadDir(storageRoot)
+func (s *Storage) move(from, to string) error {
+	r, err := s.backend.Open(from)
 	if err != nil {
-		return "", "", err
+		return err
 	}
+	defer r.Close()
 
-	for _, e := range entries {
-		fileName := strings.Split(e.Name(), "_")
+	w, err := s.backend.Create(to)
+	if err != nil {
+		return err
+	}
 
-		if fileName[0] == id {
-			fullPath = filepath.Join(storageRoot, e.Name())
-			originalName = fileName[1]
+	if _, err := io.Copy(w, r); err != nil {
+		w.Abort()
+		return err
+	}
 
-			return fullPath, originalName, nil
-		}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return s.backend.Delete(from)
+}
+
+// scanBlob reads a blob back to recover the metadata of the version it holds.
+func (s *Storage) scanBlob(name string) (*Version, error) {
+	info, err := s.backend.Stat(blobKey(name))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.backend.Open(blobKey(name))
+	if err != nil {
+		return nil, err
 	}
+	defer r.Close()
 
-	return "", "", err
-}
\ No newline at end of file
+	h := sha256.New()
+	head := &sniffBuffer{}
+	if _, err := io.Copy(io.MultiWriter(h, head), r); err != nil {
+		return nil, err
+	}
+
+	v := &Version{
+		Version:     1,
+		Blob:        name,
+		Size:        info.Size,
+		ContentType: detectContentType(name, head.data),
+		Checksum:    hex.EncodeToString(h.Sum(nil)),
+		CreatedAt:   info.ModTime,
+	}
+
+	return v, nil
+}
+
+// detectContentType trusts a known file extension first and falls back to
+// sniffing the leading bytes of the content.
+func detectContentType(name string, head []byte) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+
+	return http.DetectContentType(head)
+}
+
+type sniffBuffer struct {
+	data []byte
+}
+
+func (b *sniffBuffer) Write(p []byte) (int, error) {
+	if rest := sniffLen - len(b.data); rest > 0 {
+		b.data = append(b.data, p[:min(rest, len(p))]...)
+	}
+
+	return len(p), nil
+}
+
+func blobKey(name string) string {
+	return blobsPrefix + name
+}
+
+func incomingKey() string {
+	return incomingPrefix + uuid.NewString()
+}
+
+type Writer struct {
+	storage *Storage
+	blob    BlobWriter
+	// incoming is where blob is written until its hash is known.
+	incoming string
+	hash     hash.Hash
+	head     sniffBuffer
+	meta     *FileMeta
+
+	// update is set when the upload becomes a new version of meta.ID
+	// rather than a new file.
+	update bool
+
+	// reserved is how many bytes this upload holds against the quota.
+	reserved int64
+	released bool
+}
+
+func (w *Writer) ID() string {
+	return w.meta.ID
+}
+
+// Version is the version number the upload got. It is only known after
+// Commit.
+func (w *Writer) Version() int64 {
+	return w.meta.Version
+}
+
+// Size is the number of bytes written so far.
+func (w *Writer) Size() int64 {
+	return w.meta.Size
+}
+
+// Checksum is the hex SHA-256 of everything written so far.
+func (w *Writer) Checksum() string {
+	return hex.EncodeToString(w.hash.Sum(nil))
+}
+
+// Verify compares the written content with the digest the client expects.
+// An empty expected digest means the client did not send one.
+func (w *Writer) Verify(expected string) error {
+	if expected == "" || strings.EqualFold(expected, w.Checksum()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, w.Checksum())
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if err := w.storage.reserveBytes(w.meta.Namespace, int64(len(p))); err != nil {
+		return 0, err
+	}
+	w.reserved += int64(len(p))
+
+	n, err := w.blob.Write(p)
+	w.hash.Write(p[:n])
+	w.head.Write(p[:n])
+	w.meta.Size += int64(n)
+
+	return n, err
+}
+
+// Commit makes the blob durable and only then adds it to the index, so
+// List and Download never see a file that is still being written.
+func (w *Writer) Commit() error {
+	if err := w.blob.Close(); err != nil {
+		w.storage.mu.Lock()
+		w.release()
+		w.storage.mu.Unlock()
+		return err
+	}
+
+	now := time.Now()
+	w.meta.Version = 1
+	w.meta.Blob = w.Checksum()
+	w.meta.Checksum = w.Checksum()
+	w.meta.ContentType = detectContentType(w.meta.Name, w.head.data)
+	w.meta.CreatedAt = now
+	w.meta.UpdatedAt = now
+
+	meta, err := w.storage.add(w)
+	if err != nil {
+		return err
+	}
+	w.meta = meta
+
+	return nil
+}
+
+func (w *Writer) Abort() error {
+	w.storage.mu.Lock()
+	w.release()
+	w.storage.mu.Unlock()
+
+	return w.blob.Abort()
+}
+
+// release gives the reservation back. Callers must hold storage.mu.
+func (w *Writer) release() {
+	if w.released {
+		return
+	}
+
+	files := int64(1)
+	if w.update {
+		files = 0
+	}
+
+	w.storage.release(w.meta.Namespace, files, w.reserved)
+	w.released = true
+}
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T, backend Backend, opts Options) *Storage {
+	t.Helper()
+
+	s, err := New(backend, opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return s
+}
+
+func upload(t *testing.T, s *Storage, namespace, name, content string) *FileMeta {
+	t.Helper()
+
+	w, err := s.CreateFile(namespace, name)
+	if err != nil {
+		t.Fatalf("CreateFile(%s, %s): %v", namespace, name, err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	meta, err := s.FindFileByID(namespace, w.ID())
+	if err != nil {
+		t.Fatalf("FindFileByID after commit: %v", err)
+	}
+
+	return meta
+}
+
+func readFile(t *testing.T, s *Storage, namespace, id string) string {
+	t.Helper()
+
+	r, _, err := s.Open(namespace, id)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", id, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", id, err)
+	}
+
+	return string(data)
+}
+
+func blobExists(t *testing.T, backend Backend, name string) bool {
+	t.Helper()
+
+	_, err := backend.Stat(blobKey(name))
+	if errors.Is(err, ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		t.Fatalf("Stat(%s): %v", name, err)
+	}
+
+	return true
+}
+
+func TestRebuild(t *testing.T) {
+	tests := []struct {
+		name string
+		// damage changes the backend behind the index between two starts.
+		damage func(t *testing.T, backend Backend, meta *FileMeta)
+		// namespace is where the file is indexed after the restart, empty
+		// if it must be gone. Only in its own namespace does it keep its ID
+		// and name.
+		namespace string
+	}{
+		{
+			name:      "index kept",
+			damage:    func(*testing.T, Backend, *FileMeta) {},
+			namespace: DefaultNamespace,
+		},
+		{
+			name: "index lost, journal kept",
+			damage: func(t *testing.T, backend Backend, _ *FileMeta) {
+				if err := backend.Delete(indexKey); err != nil {
+					t.Fatal(err)
+				}
+			},
+			namespace: DefaultNamespace,
+		},
+		{
+			// Nothing tells which file the blob belonged to any more, so
+			// it is kept aside under a new ID; its name is lost.
+			name: "index and journal lost",
+			damage: func(t *testing.T, backend Backend, _ *FileMeta) {
+				for _, key := range append(journalKeys(t, backend), indexKey) {
+					if err := backend.Delete(key); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			namespace: LostFoundNamespace,
+		},
+		{
+			name: "blob lost",
+			damage: func(t *testing.T, backend Backend, meta *FileMeta) {
+				if err := backend.Delete(blobKey(meta.blob())); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemory()
+			s := newTestStorage(t, backend, Options{})
+			meta := upload(t, s, DefaultNamespace, "my_file.txt", "hello")
+
+			tt.damage(t, backend, meta)
+
+			s = newTestStorage(t, backend, Options{})
+
+			namespace := tt.namespace
+			if namespace == "" {
+				namespace = DefaultNamespace
+			}
+
+			files, _, err := s.ListFiles(namespace, ListOptions{Limit: 10})
+			if err != nil {
+				t.Fatalf("ListFiles(%s): %v", namespace, err)
+			}
+
+			if tt.namespace == "" {
+				if len(files) != 0 {
+					t.Fatalf("%d files indexed after restart, want none", len(files))
+				}
+				return
+			}
+
+			if len(files) != 1 {
+				t.Fatalf("%d files in %s after restart, want 1", len(files), namespace)
+			}
+			got := files[0]
+			if namespace == DefaultNamespace && (got.ID != meta.ID || got.Name != meta.Name) {
+				t.Errorf("indexed as %s %q after restart, want %s %q", got.ID, got.Name, meta.ID, meta.Name)
+			}
+			if !blobExists(t, backend, meta.blob()) {
+				t.Error("blob was deleted")
+			}
+			if content := readFile(t, s, namespace, got.ID); content != "hello" {
+				t.Errorf("content = %q, want %q", content, "hello")
+			}
+		})
+	}
+}
+
+// Files of the old "<uuid>_<name>" layout keep their name, underscores
+// included, and their age.
+func TestRebuildMigratesLegacyFiles(t *testing.T) {
+	root := t.TempDir()
+
+	const id = "6e306d79-4648-4f05-a3f7-e002b1dee4ec"
+	path := filepath.Join(root, id+"_my_old_file.tmp")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, created, created); err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := NewFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestStorage(t, backend, Options{})
+
+	meta, err := s.FindFileByID(DefaultNamespace, id)
+	if err != nil {
+		t.Fatalf("legacy file not indexed: %v", err)
+	}
+	if meta.Name != "my_old_file.tmp" {
+		t.Errorf("name = %q, want %q", meta.Name, "my_old_file.tmp")
+	}
+	if !meta.CreatedAt.Equal(created) {
+		t.Errorf("created at %v, want %v", meta.CreatedAt, created)
+	}
+	if got := readFile(t, s, DefaultNamespace, id); got != "old" {
+		t.Errorf("content = %q, want %q", got, "old")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("legacy file left in place: %v", err)
+	}
+}
+
+func TestFindFileByIDNotFound(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{})
+	upload(t, s, DefaultNamespace, "a.txt", "hello")
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"unknown id", "00000000-0000-0000-0000-000000000000"},
+		{"empty id", ""},
+		{"not an id", "../index.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.FindFileByID(DefaultNamespace, tt.id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("FindFileByID = %v, want ErrNotFound", err)
+			}
+			if _, _, err := s.Open(DefaultNamespace, tt.id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Open = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{})
+	meta := upload(t, s, DefaultNamespace, "a.txt", "hello")
+
+	if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := backend.Stat(blobKey(meta.ID)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(blob) after Delete = %v, want ErrNotFound", err)
+	}
+
+	// The deletion is journaled, so it survives a restart.
+	s = newTestStorage(t, backend, Options{})
+	if _, err := s.FindFileByID(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindFileByID after restart = %v, want ErrNotFound", err)
+	}
+}
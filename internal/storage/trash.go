@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Trash lists the deleted files of a namespace that can still be restored,
+// most recently deleted first.
+func (s *Storage) Trash(namespace string) ([]*FileMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return nil, ErrNamespaceNotFound
+	}
+
+	var list []*FileMeta
+	for _, meta := range s.trash {
+		if meta.Namespace == namespace {
+			list = append(list, meta)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].DeletedAt.Equal(list[j].DeletedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].DeletedAt.After(list[j].DeletedAt)
+	})
+
+	return list, nil
+}
+
+// Restore brings a deleted file back from the trash with all its versions.
+// It counts against the quota again, so it fails if the file no longer fits.
+func (s *Storage) Restore(namespace, id string) (*FileMeta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trashed, ok := s.trash[id]
+	if !ok || trashed.Namespace != namespace {
+		return nil, ErrNotFound
+	}
+
+	if _, ok := s.namespaces[namespace]; !ok {
+		return nil, ErrNamespaceNotFound
+	}
+
+	u := s.usageOf(namespace)
+	if err := s.checkFiles(namespace, u, 1); err != nil {
+		return nil, err
+	}
+	if err := s.checkBytes(namespace, u, trashed.storedSize()); err != nil {
+		return nil, err
+	}
+
+	meta := *trashed
+	meta.DeletedAt = time.Time{}
+
+	delete(s.trash, id)
+
+	if _, err := s.replace(&meta); err != nil {
+		s.trash[id] = trashed
+		return nil, err
+	}
+
+	// The restored entry took over the references of the trashed one, so
+	// this frees nothing.
+	s.unlink(trashed)
+
+	return &meta, nil
+}
+
+// PurgeTrash permanently removes files that were deleted before the
+// retention period, counted back from now, and returns how many were
+// removed.
+func (s *Storage) PurgeTrash(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-s.trashRetention)
+
+	var expired []*FileMeta
+	for _, meta := range s.trash {
+		if meta.DeletedAt.Before(cutoff) {
+			expired = append(expired, meta)
+		}
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	return s.purge(expired)
+}
+
+// purge removes trashed files for good along with their blobs, and returns
+// how many were removed. Callers must hold s.mu.
+func (s *Storage) purge(list []*FileMeta) (int, error) {
+	for _, meta := range list {
+		delete(s.trash, meta.ID)
+	}
+
+	if err := s.save(fileIDs(list)...); err != nil {
+		for _, meta := range list {
+			s.trash[meta.ID] = meta
+		}
+		return 0, err
+	}
+
+	var errs []error
+	for _, meta := range list {
+		if err := s.deleteBlobs(s.unlink(meta)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return len(list), errors.Join(errs...)
+}
+
+// PurgeAt is when a trashed file is due to be removed for good.
+func (s *Storage) PurgeAt(meta *FileMeta) time.Time {
+	return meta.DeletedAt.Add(s.trashRetention)
+}
+
+// moveToTrash hides a file from everything but Trash and Restore. Its blobs
+// are kept until the trash is purged. Callers must hold s.mu.
+func (s *Storage) moveToTrash(meta *FileMeta) error {
+	trashed := *meta
+	trashed.DeletedAt = time.Now()
+
+	delete(s.files, meta.ID)
+	s.account(meta, -1)
+	s.trash[meta.ID] = &trashed
+
+	if err := s.save(meta.ID); err != nil {
+		delete(s.trash, meta.ID)
+		s.files[meta.ID] = meta
+		s.account(meta, 1)
+		return err
+	}
+
+	// The trashed entry takes over the blob references, but not the
+	// content CreateFromDigest may copy.
+	s.countContent(meta, -1)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	backend := NewMemory()
+	opts := Options{TrashRetention: time.Hour}
+	s := newTestStorage(t, backend, opts)
+
+	meta := upload(t, s, DefaultNamespace, "a.txt", "hello")
+	if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := s.FindFileByID(DefaultNamespace, meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindFileByID of a trashed file = %v, want ErrNotFound", err)
+	}
+	if !blobExists(t, backend, meta.blob()) {
+		t.Fatal("blob of a trashed file was deleted")
+	}
+
+	// The trash is journaled, so it survives a restart.
+	s = newTestStorage(t, backend, opts)
+	trash, err := s.Trash(DefaultNamespace)
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != meta.ID || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("Trash after restart = %+v, want %s", trash, meta.ID)
+	}
+	if u := usage(t, s, DefaultNamespace); u.Files != 0 || u.Bytes != 0 {
+		t.Errorf("usage with a trashed file = %+v, want zero", u)
+	}
+
+	if _, err := s.Restore("other", meta.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Restore from another namespace = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Restore(DefaultNamespace, meta.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := readFile(t, s, DefaultNamespace, meta.ID); got != "hello" {
+		t.Errorf("content after Restore = %q, want %q", got, "hello")
+	}
+
+	s = newTestStorage(t, backend, opts)
+	if _, err := s.FindFileByID(DefaultNamespace, meta.ID); err != nil {
+		t.Errorf("restored file lost on restart: %v", err)
+	}
+}
+
+func TestRestoreQuota(t *testing.T) {
+	s := newTestStorage(t, NewMemory(), Options{Quota: Quota{MaxFiles: 1}, TrashRetention: time.Hour})
+
+	meta := upload(t, s, DefaultNamespace, "a.txt", "hello")
+	if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+		t.Fatal(err)
+	}
+	upload(t, s, DefaultNamespace, "b.txt", "world")
+
+	if _, err := s.Restore(DefaultNamespace, meta.ID); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Restore over quota = %v, want ErrQuotaExceeded", err)
+	}
+	if trash, _ := s.Trash(DefaultNamespace); len(trash) != 1 {
+		t.Errorf("failed Restore took the file out of the trash")
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	backend := NewMemory()
+	opts := Options{TrashRetention: time.Hour}
+	s := newTestStorage(t, backend, opts)
+
+	old := upload(t, s, DefaultNamespace, "old.txt", "old")
+	if err := s.Delete(DefaultNamespace, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	recent := upload(t, s, DefaultNamespace, "recent.txt", "recent")
+	if err := s.Delete(DefaultNamespace, recent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// An hour after the second deletion only the first file has been in the
+	// trash for longer than the retention period.
+	trash, _ := s.Trash(DefaultNamespace)
+	now := trash[0].DeletedAt.Add(time.Hour)
+	if n, err := s.PurgeTrash(now); err != nil || n != 1 {
+		t.Fatalf("PurgeTrash = %d, %v, want 1, nil", n, err)
+	}
+
+	if blobExists(t, backend, old.blob()) {
+		t.Error("blob of a purged file is still stored")
+	}
+	if !blobExists(t, backend, recent.blob()) {
+		t.Error("blob of a file still in the trash was deleted")
+	}
+
+	s = newTestStorage(t, backend, opts)
+	trash, err := s.Trash(DefaultNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != recent.ID {
+		t.Errorf("Trash after purge and restart = %+v, want only %s", trash, recent.ID)
+	}
+}
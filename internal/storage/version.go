@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrVersionNotFound = errors.New("version not found")
+
+// Version is one stored revision of a file's content.
+type Version struct {
+	Version     int64     `json:"version"`
+	Blob        string    `json:"blob"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UpdateFile starts an upload that becomes the next version of an existing
+// file once committed. An empty fileName keeps the current name.
+func (s *Storage) UpdateFile(namespace, id, fileName string) (*Writer, error) {
+	meta, err := s.FindFileByID(namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if fileName == "" {
+		fileName = meta.Name
+	}
+
+	// The new content goes to a blob of its own, so readers of the previous
+	// version are never affected by the upload.
+	incoming := incomingKey()
+
+	blob, err := s.backend.Create(incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		storage:  s,
+		blob:     blob,
+		incoming: incoming,
+		hash:     sha256.New(),
+		meta:     &FileMeta{ID: id, Namespace: namespace, Name: fileName},
+		update:   true,
+	}
+
+	return w, nil
+}
+
+// Versions lists every version of a file, the current one first.
+func (s *Storage) Versions(namespace, id string) ([]*Version, error) {
+	meta, err := s.FindFileByID(namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*Version{meta.current()}, meta.History...), nil
+}
+
+// OpenVersion is Open for a given version; 0 means the current one. The
+// returned metadata describes that version.
+func (s *Storage) OpenVersion(namespace, id string, version int64) (io.ReadSeekCloser, *FileMeta, error) {
+	meta, err := s.FindVersion(namespace, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blob, err := s.backend.Open(blobKey(meta.blob()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blob, meta, nil
+}
+
+// FindVersion is FindFileByID for a given version; 0 means the current one.
+func (s *Storage) FindVersion(namespace, id string, version int64) (*FileMeta, error) {
+	meta, err := s.FindFileByID(namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 || version == meta.Version {
+		return meta, nil
+	}
+
+	v := meta.version(version)
+	if v == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	view := *meta
+	view.setCurrent(v)
+	view.History = nil
+
+	return &view, nil
+}
+
+// RestoreVersion makes the content of an old version current again. It is
+// recorded as a new version that shares the old version's blob, so nothing
+// is copied and the history stays intact.
+func (s *Storage) RestoreVersion(namespace, id string, version int64) (*FileMeta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.files[id]
+	if !ok || current.Namespace != namespace {
+		return nil, ErrNotFound
+	}
+
+	if version == current.Version {
+		return current, nil
+	}
+
+	old := current.version(version)
+	if old == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	restored := *old
+	restored.CreatedAt = time.Now()
+
+	meta := current.withVersion(&restored, current.Name, s.maxVersions)
+
+	unused, err := s.replace(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteBlobs(unused)
+
+	return meta, nil
+}
+
+func newFileMeta(id string, v *Version) *FileMeta {
+	meta := &FileMeta{
+		ID:        id,
+		Namespace: DefaultNamespace,
+		Name:      id,
+		CreatedAt: v.CreatedAt,
+	}
+	meta.setCurrent(v)
+
+	return meta
+}
+
+// withVersion returns a copy of m with v as its current version and the
+// previous one moved to the history, trimmed to keep versions (0 keeps all).
+// Blobs only the trimmed versions used are released by replace.
+func (m *FileMeta) withVersion(v *Version, name string, keep int) *FileMeta {
+	next := *m
+	next.Name = name
+	next.History = append([]*Version{m.current()}, m.History...)
+
+	v.Version = m.Version + 1
+	next.setCurrent(v)
+
+	if keep > 0 && len(next.History) > keep {
+		next.History = next.History[:keep:keep]
+	}
+
+	return &next
+}
+
+func (m *FileMeta) current() *Version {
+	return &Version{
+		Version:     m.Version,
+		Blob:        m.blob(),
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Checksum:    m.Checksum,
+		CreatedAt:   m.UpdatedAt,
+	}
+}
+
+func (m *FileMeta) setCurrent(v *Version) {
+	m.Version = v.Version
+	m.Blob = v.Blob
+	m.Size = v.Size
+	m.ContentType = v.ContentType
+	m.Checksum = v.Checksum
+	m.UpdatedAt = v.CreatedAt
+}
+
+func (m *FileMeta) version(version int64) *Version {
+	for _, v := range m.History {
+		if v.Version == version {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (m *FileMeta) blob() string {
+	if m.Blob == "" {
+		return m.ID
+	}
+
+	return m.Blob
+}
+
+// eachBlob calls fn once for every blob the file references. Versions with
+// the same content, a restored one for instance, share a blob.
+func (m *FileMeta) eachBlob(fn func(name string, size int64)) {
+	seen := map[string]bool{m.blob(): true}
+	fn(m.blob(), m.Size)
+
+	for _, v := range m.History {
+		if !seen[v.Blob] {
+			seen[v.Blob] = true
+			fn(v.Blob, v.Size)
+		}
+	}
+}
+
+// blobs lists every blob the file references, each once.
+func (m *FileMeta) blobs() []string {
+	var names []string
+	m.eachBlob(func(name string, _ int64) {
+		names = append(names, name)
+	})
+
+	return names
+}
+
+// storedSize is how many bytes the file occupies with all its versions. It
+// does not depend on other files sharing the same content.
+func (m *FileMeta) storedSize() int64 {
+	var size int64
+	m.eachBlob(func(_ string, n int64) {
+		size += n
+	})
+
+	return size
+}
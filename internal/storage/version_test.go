@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func update(t *testing.T, s *Storage, namespace, id, content string) *FileMeta {
+	t.Helper()
+
+	w, err := s.UpdateFile(namespace, id, "")
+	if err != nil {
+		t.Fatalf("UpdateFile(%s): %v", id, err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	meta, err := s.FindFileByID(namespace, id)
+	if err != nil {
+		t.Fatalf("FindFileByID after update: %v", err)
+	}
+
+	return meta
+}
+
+func readVersion(t *testing.T, s *Storage, id string, version int64) string {
+	t.Helper()
+
+	r, _, err := s.OpenVersion(DefaultNamespace, id, version)
+	if err != nil {
+		t.Fatalf("OpenVersion(%s, %d): %v", id, version, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s@%d: %v", id, version, err)
+	}
+
+	return string(data)
+}
+
+func TestVersions(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{})
+
+	meta := upload(t, s, DefaultNamespace, "a.txt", "one")
+	meta = update(t, s, DefaultNamespace, meta.ID, "two")
+
+	if meta.Version != 2 || meta.Name != "a.txt" {
+		t.Fatalf("after update: version %d, name %q", meta.Version, meta.Name)
+	}
+	if got := readFile(t, s, DefaultNamespace, meta.ID); got != "two" {
+		t.Errorf("current content = %q, want %q", got, "two")
+	}
+	if got := readVersion(t, s, meta.ID, 1); got != "one" {
+		t.Errorf("version 1 content = %q, want %q", got, "one")
+	}
+	if _, err := s.FindVersion(DefaultNamespace, meta.ID, 7); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("FindVersion(7) = %v, want ErrVersionNotFound", err)
+	}
+
+	restored, err := s.RestoreVersion(DefaultNamespace, meta.ID, 1)
+	if err != nil {
+		t.Fatalf("RestoreVersion: %v", err)
+	}
+	if restored.Version != 3 || restored.Size != 3 {
+		t.Errorf("restored: version %d, size %d, want 3, 3", restored.Version, restored.Size)
+	}
+	if got := readFile(t, s, DefaultNamespace, meta.ID); got != "one" {
+		t.Errorf("content after restore = %q, want %q", got, "one")
+	}
+
+	// The history is journaled, so it survives a restart.
+	s = newTestStorage(t, backend, Options{})
+	versions, err := s.Versions(DefaultNamespace, meta.ID)
+	if err != nil {
+		t.Fatalf("Versions after restart: %v", err)
+	}
+	var got []int64
+	for _, v := range versions {
+		got = append(got, v.Version)
+	}
+	if len(got) != 3 || got[0] != 3 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("versions after restart = %v, want [3 2 1]", got)
+	}
+	if got := readVersion(t, s, meta.ID, 2); got != "two" {
+		t.Errorf("version 2 after restart = %q, want %q", got, "two")
+	}
+
+	if err := s.Delete(DefaultNamespace, meta.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, v := range versions {
+		if blobExists(t, backend, v.Blob) {
+			t.Errorf("blob of version %d left after Delete", v.Version)
+		}
+	}
+}
+
+func TestMaxVersions(t *testing.T) {
+	backend := NewMemory()
+	s := newTestStorage(t, backend, Options{MaxVersions: 1})
+
+	meta := upload(t, s, DefaultNamespace, "a.txt", "one")
+	first := meta.blob()
+	meta = update(t, s, DefaultNamespace, meta.ID, "two")
+	second := meta.blob()
+
+	// Restoring version 1 keeps its blob alive even though version 1
+	// itself is trimmed from the history.
+	if _, err := s.RestoreVersion(DefaultNamespace, meta.ID, 1); err != nil {
+		t.Fatalf("RestoreVersion: %v", err)
+	}
+	if !blobExists(t, backend, first) {
+		t.Errorf("blob shared with the current version was deleted")
+	}
+
+	meta = update(t, s, DefaultNamespace, meta.ID, "four")
+	if len(meta.History) != 1 || meta.History[0].Version != 3 {
+		t.Fatalf("history = %+v, want only version 3", meta.History)
+	}
+	if blobExists(t, backend, second) {
+		t.Errorf("blob of trimmed version 2 was kept")
+	}
+	if got := readVersion(t, s, meta.ID, 3); got != "one" {
+		t.Errorf("version 3 content = %q, want %q", got, "one")
+	}
+	if _, err := s.FindVersion(DefaultNamespace, meta.ID, 2); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("FindVersion(2) = %v, want ErrVersionNotFound", err)
+	}
+}